@@ -3,83 +3,90 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"time"
+	"path/filepath"
 
+	"github.com/nikgalushko/gan-ilox/compiler"
 	"github.com/nikgalushko/gan-ilox/debug"
 	"github.com/nikgalushko/gan-ilox/env"
 	"github.com/nikgalushko/gan-ilox/internal"
 	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/nikgalushko/gan-ilox/modules"
+	"github.com/nikgalushko/gan-ilox/natives"
 	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/resolver"
 	"github.com/nikgalushko/gan-ilox/scanner"
+	"github.com/nikgalushko/gan-ilox/vm"
 )
 
+var (
+	backend = flag.String("backend", "tree", "execution backend: tree or vm")
+	path    = flag.String("path", "", "list of directories where imported modules are searched, separated by "+string(filepath.ListSeparator))
+)
+
+// stdin is shared by the prompt and scripts, so lines which are read by readLine are not read by the prompt.
+var stdin = bufio.NewReader(os.Stdin)
+
 func main() {
 	var err error
-	args := os.Args[1:] // cut programm name
-	environment := env.New()
-	environment.Define("now", internal.NewLiteralNativeFunction(
-		nil, func(args ...internal.Literal) (internal.Literal, error) {
-			return internal.NewLiteralInt(time.Now().UnixMilli()), nil
-		},
-	))
-
-	environment.Define("sleep", internal.NewLiteralNativeFunction(
-		[]string{"seconds"}, func(args ...internal.Literal) (internal.Literal, error) {
-			if len(args) != 1 {
-				return internal.LiteralNil, errors.New("expect 1 argument got 0")
-			}
-
-			if !args[0].IsNumber() {
-				return internal.LiteralNil, errors.New("expect number as argument")
-			}
-
-			time.Sleep(time.Duration(args[0].AsInt()) * time.Second)
-			return internal.LiteralNil, nil
-		},
-	))
+	flag.Parse()
+	args := flag.Args()
+	if *backend != "tree" && *backend != "vm" {
+		fmt.Fprintln(os.Stderr, "Unknown backend:", *backend)
+		os.Exit(64)
+	}
+
+	builtins := env.New()
+	natives.Builtins().Define(builtins)
+	environment := env.NewWithParent(builtins)
 
+	loader := modules.New(builtins, filepath.SplitList(*path)...)
+	natives.Std().Install(loader)
 	if len(args) > 1 {
-		fmt.Println("Usage: gan-ilox [script]")
+		fmt.Fprintln(os.Stderr, "Usage: gan-ilox [--backend=tree|vm] [--path=dirs] [script]")
 		os.Exit(64)
 	} else if len(args) == 1 {
-		err = runFile(environment, args[0])
+		err = runFile(environment, loader, args[0])
 	} else {
-		err = runPrompt(environment)
+		err = runPrompt(environment, loader)
 	}
 
 	if err != nil {
-		fmt.Println(err.Error())
+		fmt.Fprintln(os.Stderr, err.Error())
 	}
 }
 
-func runFile(env *env.Environment, filename string) error {
+func runFile(env *env.Environment, loader *modules.Loader, filename string) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
 
-	return run(env, string(data), false)
+	return run(env, loader, string(data), filename)
 }
 
-func runPrompt(env *env.Environment) error {
-	s := bufio.NewScanner(os.Stdin)
-
-	fmt.Print("> ")
+func runPrompt(env *env.Environment, loader *modules.Loader) error {
+	for {
+		fmt.Print("> ")
+		line, err := stdin.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
 
-	for s.Scan() {
-		err := run(env, s.Text(), true)
-		if err != nil {
+		if err := run(env, loader, line, ""); err != nil {
 			return err
 		}
-		fmt.Print("> ")
 	}
-
-	return s.Err()
 }
 
-func run(env *env.Environment, source string, isPrompt bool) error {
+// run executes the source; filename is empty for the source from the prompt.
+func run(env *env.Environment, loader *modules.Loader, source string, filename string) error {
 	s := scanner.NewScanner(source)
 	tokens, err := s.ScanTokens()
 	if err != nil {
@@ -92,14 +99,15 @@ func run(env *env.Environment, source string, isPrompt bool) error {
 		return err
 	}
 
-	fmt.Println("__debug__", debug.AstPrinter{S: stmts})
-	i := interpreter.New(env, stmts)
-	ret, err := i.Interpret()
+	stmts, err = resolver.New().Resolve(stmts)
 	if err != nil {
-		fmt.Println(err.Error())
+		return err
 	}
 
-	if len(ret) != 0 && isPrompt {
+	fmt.Println("__debug__", debug.AstPrinter{S: stmts})
+	// uncaught errors are reported to stderr by backends
+	ret, _ := execute(env, loader, stmts, filename)
+	if len(ret) != 0 && filename == "" {
 		for _, r := range ret {
 			fmt.Println(r)
 		}
@@ -107,3 +115,18 @@ func run(env *env.Environment, source string, isPrompt bool) error {
 
 	return nil
 }
+
+// execute runs statements by the backend which is chosen by the flag.
+func execute(env *env.Environment, loader *modules.Loader, stmts []internal.Stmt, filename string) ([]any, error) {
+	if *backend == "vm" {
+		script, err := compiler.New().Compile(stmts)
+		if err != nil {
+			interpreter.Report(os.Stderr, err)
+			return nil, err
+		}
+
+		return vm.New(env, vm.WithFile(filename), vm.WithModules(loader), vm.WithStdin(stdin)).Run(script)
+	}
+
+	return interpreter.New(env, stmts, interpreter.WithFile(filename), interpreter.WithModules(loader), interpreter.WithStdin(stdin)).Interpret()
+}
@@ -0,0 +1,113 @@
+package compiler
+
+import (
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+type OpCode byte
+
+// Operands follow the opcode in the code; u8 is one byte and u16 is two bytes in big-endian order.
+const (
+	// OpConstant u16 pushes the constant.
+	OpConstant OpCode = iota
+	OpNil
+	OpTrue
+	OpFalse
+	OpPop
+	// OpGetLocal u8 pushes the local from the slot of the current frame.
+	OpGetLocal
+	// OpSetLocal u8 stores the top of the stack to the slot of the current frame.
+	OpSetLocal
+	// OpGetUpvalue u8 pushes the upvalue of the current closure.
+	OpGetUpvalue
+	// OpSetUpvalue u8 stores the top of the stack to the upvalue of the current closure.
+	OpSetUpvalue
+	// OpDefineGlobal u16 pops the value and defines the global with the name from the constant.
+	OpDefineGlobal
+	// OpGetGlobal u16 pushes the global with the name from the constant.
+	OpGetGlobal
+	// OpSetGlobal u16 stores the top of the stack to the global with the name from the constant.
+	OpSetGlobal
+	// OpGetProperty u16 replaces the instance by its field or bound method.
+	OpGetProperty
+	// OpSetProperty u16 pops the value and the instance, sets the field and pushes nil.
+	OpSetProperty
+	// OpGetSuper u16 pops the superclass and the instance and pushes the method of the superclass bound to the instance.
+	OpGetSuper
+	// OpBinary u8 pops two operands and pushes the result of the binary operator.
+	OpBinary
+	// OpUnary u8 replaces the operand by the result of the unary operator.
+	OpUnary
+	// OpJump u16 moves forward.
+	OpJump
+	// OpJumpIfFalse u16 moves forward when the top of the stack is falsey; the value is not popped.
+	OpJumpIfFalse
+	// OpLoop u16 moves backward.
+	OpLoop
+	// OpCall u8 calls the value which is below its arguments.
+	OpCall
+	// OpClosure u16 pushes a closure of the function; it is followed by a pair of u8 for each upvalue:
+	// 1 if the upvalue captures a local of the enclosing function and 0 if it captures its upvalue, and the index.
+	OpClosure
+	// OpCloseUpvalue moves the top of the stack to the heap and pops it.
+	OpCloseUpvalue
+	OpReturn
+	// OpClass u16 u8 creates a class with the name from the constant; the second operand is a number of methods
+	// which is followed by u16 constant with the name of each method. The superclass or nil and method closures
+	// are popped from the stack.
+	OpClass
+	// OpList u16 replaces the elements by the list.
+	OpList
+	// OpMap u16 replaces the pairs of keys and values by the map.
+	OpMap
+	// OpIndex pops the index and the collection and pushes the element.
+	OpIndex
+	// OpSetIndex pops the value, the index and the collection, sets the element and pushes the value.
+	OpSetIndex
+	// OpSlice pops the high and the low bounds and the list and pushes the slice.
+	OpSlice
+	// OpInterpolate u16 replaces the values by the concatenation of their string forms.
+	OpInterpolate
+	OpPrint
+	// OpResult pops the value of a top-level expression statement and keeps it as a result of the script.
+	OpResult
+	// OpPushCatch u16 installs the handler which moves forward to the catch clause with the caught value on the stack.
+	OpPushCatch
+	// OpPushFinally u16 installs the handler which moves forward to the finally clause with the index
+	// of the pending error on the stack.
+	OpPushFinally
+	// OpPopHandler removes the innermost handler.
+	OpPopHandler
+	// OpThrow pops the value and throws it.
+	OpThrow
+	// OpRethrow pops the index of the pending error and throws the error again.
+	OpRethrow
+	// OpImport u16 pushes the module with the path from the constant.
+	OpImport
+	// OpImportName u16 replaces the module by its export with the name from the constant.
+	OpImportName
+)
+
+// Span is a place in the source code which produced an instruction.
+type Span struct {
+	internal.Position
+	// Near is reported by runtime errors; see interpreter.RuntimeError.
+	Near string
+}
+
+// Chunk is a compiled code of a function.
+type Chunk struct {
+	Code      []byte
+	Constants []internal.Literal
+	Functions []*Function
+	// Spans has the same length as Code; each byte has the span of its instruction.
+	Spans []Span
+}
+
+// Function is a compiled function; it becomes a closure at runtime.
+type Function struct {
+	Name       string
+	Parameters []string
+	Upvalues   int
+	Chunk      Chunk
+}
@@ -0,0 +1,929 @@
+package compiler
+
+import (
+	"errors"
+	"math"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/token/kind"
+)
+
+type functionKind int8
+
+const (
+	kindScript functionKind = iota
+	kindFunction
+	kindMethod
+	kindInitializer
+)
+
+type local struct {
+	name string
+	// depth is a depth of the scope where the local is declared; it is -1 until the local is initialized.
+	depth int
+	// captured is true when a closure captures the local, so it must be moved to the heap at the end of the scope.
+	captured bool
+}
+
+type upvalue struct {
+	index   byte
+	isLocal bool
+}
+
+type loop struct {
+	// scopeDepth is a depth of the scope around the body of the loop.
+	scopeDepth int
+	// continueTarget is an offset of the code which is executed by continue; it is -1 until it is compiled.
+	continueTarget int
+	breaks         []int
+	continues      []int
+	// span is a place of the loop keyword; jumps back to the start of the loop are reported there.
+	span Span
+}
+
+type tryBlock struct {
+	// finally is nil when the try statement has no finally clause.
+	finally internal.Stmt
+	// handlers is a number of handlers which are installed by the try statement at the moment.
+	handlers int
+	// loops is a number of loops around the try statement inside the function.
+	loops int
+	// locals is a number of locals which are declared before the try statement.
+	locals int
+}
+
+// function is a state of the function which is being compiled.
+type function struct {
+	enclosing  *function
+	fn         *Function
+	kind       functionKind
+	locals     []local
+	upvalues   []upvalue
+	scopeDepth int
+	loops      []*loop
+	tries      []*tryBlock
+	names      map[string]uint16
+}
+
+// Compiler lowers statements to the bytecode.
+// It resolves variables by itself, so it does not depend on depths which are set by resolver;
+// but statements must be checked by resolver before compilation.
+type Compiler struct {
+	current *function
+	// span is attached to each emitted byte.
+	span Span
+	err  error
+}
+
+func New() *Compiler {
+	return &Compiler{}
+}
+
+// Compile returns the function which executes stmts as a script.
+func (c *Compiler) Compile(stmts []internal.Stmt) (*Function, error) {
+	c.begin(&Function{}, kindScript)
+	for _, s := range stmts {
+		c.stmt(s)
+	}
+	fn, _ := c.end()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return fn, nil
+}
+
+func (c *Compiler) VisitStmtExpression(s internal.StmtExpression) any {
+	c.expr(s.Expression)
+	if c.current.kind == kindScript && c.current.scopeDepth == 0 {
+		c.emit(OpResult)
+	} else {
+		c.emit(OpPop)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitPrintStmt(s internal.PrintStmt) any {
+	c.expr(s.Expression)
+	c.emit(OpPrint)
+
+	return nil
+}
+
+func (c *Compiler) VisitVarStmt(s internal.VarStmt) any {
+	c.declare(s.Name)
+	if s.Expression != nil {
+		c.expr(s.Expression)
+	} else {
+		c.emit(OpNil)
+	}
+	c.define(s.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitBlockStmt(s internal.BlockStmt) any {
+	c.beginScope()
+	for _, s := range s.Stmts {
+		c.stmt(s)
+	}
+	c.endScope()
+
+	return nil
+}
+
+func (c *Compiler) VisitIfStmt(s internal.IfStmt) any {
+	c.expr(s.Condition)
+	thenJump := c.emitJump(OpJumpIfFalse)
+	c.emit(OpPop)
+	c.stmt(s.If)
+
+	elseJump := c.emitJump(OpJump)
+	c.patchJump(thenJump)
+	c.emit(OpPop)
+	if s.Else != nil {
+		c.stmt(s.Else)
+	}
+	c.patchJump(elseJump)
+
+	return nil
+}
+
+func (c *Compiler) VisitElseStmt(s internal.ElseStmt) any {
+	if s.If != nil {
+		c.stmt(s.If)
+	} else {
+		c.stmt(s.Block)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitForSmt(s internal.ForStmt) any {
+	if s.Initializer != nil {
+		c.beginScope()
+		defer c.endScope()
+		c.stmt(s.Initializer)
+	}
+
+	l := c.beginLoop(Span{Position: s.Position, Near: "for"})
+	start := len(c.chunk().Code)
+	exitJump := -1
+	if s.Condition != nil {
+		c.expr(s.Condition)
+		exitJump = c.emitJump(OpJumpIfFalse)
+		c.emit(OpPop)
+	}
+
+	c.stmt(s.Body)
+
+	l.continueTarget = len(c.chunk().Code)
+	for _, j := range l.continues {
+		c.patchJump(j)
+	}
+	if s.Step != nil {
+		c.expr(s.Step)
+		c.emit(OpPop)
+	}
+	c.emitLoop(start)
+
+	if exitJump != -1 {
+		c.patchJump(exitJump)
+		c.emit(OpPop)
+	}
+	c.endLoop()
+
+	return nil
+}
+
+func (c *Compiler) VisitWhileStmt(s internal.WhileStmt) any {
+	l := c.beginLoop(Span{Position: s.Position, Near: "while"})
+	l.continueTarget = len(c.chunk().Code)
+
+	c.expr(s.Condition)
+	exitJump := c.emitJump(OpJumpIfFalse)
+	c.emit(OpPop)
+	c.stmt(s.Body)
+	c.emitLoop(l.continueTarget)
+
+	c.patchJump(exitJump)
+	c.emit(OpPop)
+	c.endLoop()
+
+	return nil
+}
+
+func (c *Compiler) VisitBreakStmt(s internal.BreakStmt) any {
+	l := c.current.loops[len(c.current.loops)-1]
+	c.exitTries(c.loopTries())
+	c.discardLocals(l.scopeDepth)
+	l.breaks = append(l.breaks, c.emitJump(OpJump))
+
+	return nil
+}
+
+func (c *Compiler) VisitContinueStmt(s internal.ContinueStmt) any {
+	l := c.current.loops[len(c.current.loops)-1]
+	c.exitTries(c.loopTries())
+	c.discardLocals(l.scopeDepth)
+	if l.continueTarget != -1 {
+		c.emitLoop(l.continueTarget)
+	} else {
+		l.continues = append(l.continues, c.emitJump(OpJump))
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitFuncStmt(s internal.FuncStmt) any {
+	c.declare(s.Name)
+	c.markInitialized()
+	c.function(s, s.Name, kindFunction)
+	c.define(s.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitReturnStmt(s internal.RreturnStmt) any {
+	if c.current.kind == kindInitializer {
+		c.emitU8(OpGetLocal, 0)
+	} else if s.Expression != nil {
+		c.expr(s.Expression)
+	} else {
+		c.emit(OpNil)
+	}
+
+	if len(c.current.tries) != 0 {
+		// the result is kept in the hidden local while finally clauses are executed
+		c.addLocal("")
+		c.markInitialized()
+		c.exitTries(0)
+		c.current.locals = c.current.locals[:len(c.current.locals)-1]
+	}
+	c.emit(OpReturn)
+
+	return nil
+}
+
+func (c *Compiler) VisitThrowStmt(s internal.ThrowStmt) any {
+	c.expr(s.Expression)
+	c.span = Span{Position: s.Position, Near: "throw"}
+	c.emit(OpThrow)
+
+	return nil
+}
+
+func (c *Compiler) VisitImportStmt(s internal.ImportStmt) any {
+	c.span = Span{Position: s.Position, Near: "import"}
+	if s.Name != "" {
+		c.declare(s.Name)
+		c.emitU16(OpImport, c.identifier(s.Path))
+		c.define(s.Name)
+	}
+	for _, name := range s.Names {
+		c.declare(name)
+		c.emitU16(OpImport, c.identifier(s.Path))
+		c.emitU16(OpImportName, c.identifier(name))
+		c.define(name)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitTryStmt(s internal.TryStmt) any {
+	t := &tryBlock{finally: s.Finally, loops: len(c.current.loops), locals: len(c.current.locals)}
+	finallyJump := -1
+	if s.Finally != nil {
+		finallyJump = c.emitJump(OpPushFinally)
+		t.handlers++
+	}
+	catchJump := -1
+	if s.Catch != nil {
+		catchJump = c.emitJump(OpPushCatch)
+		t.handlers++
+	}
+
+	c.current.tries = append(c.current.tries, t)
+	c.stmt(s.Body)
+	if s.Catch != nil {
+		c.emit(OpPopHandler)
+		t.handlers--
+		endJump := c.emitJump(OpJump)
+
+		c.patchJump(catchJump)
+		c.beginScope()
+		c.addLocal(s.Name)
+		c.markInitialized()
+		c.stmt(s.Catch)
+		c.endScope()
+		c.patchJump(endJump)
+	}
+	c.current.tries = c.current.tries[:len(c.current.tries)-1]
+
+	if s.Finally != nil {
+		c.emit(OpPopHandler)
+		c.stmt(s.Finally)
+		endJump := c.emitJump(OpJump)
+
+		// the handler jumps here with the index of the pending error in the hidden local
+		c.patchJump(finallyJump)
+		c.beginScope()
+		c.addLocal("")
+		c.markInitialized()
+		c.stmt(s.Finally)
+		c.emit(OpRethrow)
+		c.current.scopeDepth--
+		c.current.locals = c.current.locals[:len(c.current.locals)-1]
+		c.patchJump(endJump)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitClassStmt(s internal.ClassStmt) any {
+	isLocal := c.current.scopeDepth > 0
+	if isLocal {
+		// methods may refer to the class, so its slot is reserved before they are compiled
+		c.emit(OpNil)
+	}
+	c.declare(s.Name)
+	c.markInitialized()
+
+	if s.Superclass != nil {
+		c.beginScope()
+		defer c.endScope()
+
+		c.expr(s.Superclass)
+		c.addLocal("super")
+		c.markInitialized()
+		c.getVariable("super")
+	} else {
+		c.emit(OpNil)
+	}
+
+	for _, m := range s.Methods {
+		fnKind := kindMethod
+		if m.Name == "init" {
+			fnKind = kindInitializer
+		}
+		c.function(m, s.Name+"."+m.Name, fnKind)
+	}
+
+	if v, ok := s.Superclass.(internal.Variable); ok {
+		c.span = Span{Position: v.Position, Near: v.Name}
+	}
+	if len(s.Methods) > math.MaxUint8 {
+		c.error("too many methods in class")
+	}
+	c.emitU16(OpClass, c.identifier(s.Name))
+	c.emit(OpCode(len(s.Methods)))
+	for _, m := range s.Methods {
+		name := c.identifier(m.Name)
+		c.emit(OpCode(name>>8), OpCode(name))
+	}
+
+	if isLocal {
+		c.emitU8(OpSetLocal, c.slotOf(s.Name))
+		c.emit(OpPop)
+	} else {
+		c.emitU16(OpDefineGlobal, c.identifier(s.Name))
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitBinaryExpr(e internal.Binary) any {
+	c.expr(e.Left)
+	c.expr(e.Right)
+	c.span = Span{Position: e.Position, Near: e.Operator.String()}
+	c.emitU8(OpBinary, byte(e.Operator))
+
+	return nil
+}
+
+func (c *Compiler) VisitGroupingExpr(e internal.Grouping) any {
+	c.expr(e.Expression)
+	return nil
+}
+
+func (c *Compiler) VisitLiteralExpr(e internal.LiteralExpr) any {
+	switch {
+	case e.Value.IsNil():
+		c.emit(OpNil)
+	case e.Value.IsBool() && e.Value.AsBool():
+		c.emit(OpTrue)
+	case e.Value.IsBool():
+		c.emit(OpFalse)
+	default:
+		c.emitU16(OpConstant, c.constant(e.Value))
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitUnaryExpr(e internal.Unary) any {
+	c.expr(e.Right)
+	c.span = Span{Position: e.Position, Near: e.Operator.String()}
+	c.emitU8(OpUnary, byte(e.Operator))
+
+	return nil
+}
+
+func (c *Compiler) VisitVariableExpr(e internal.Variable) any {
+	c.span = Span{Position: e.Position, Near: e.Name}
+	c.getVariable(e.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitAssignmentExpr(e internal.Assignment) any {
+	c.expr(e.Expression)
+	c.span = Span{Position: e.Position, Near: e.Name}
+	c.setVariable(e.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitLogicalExpr(e internal.Logical) any {
+	c.expr(e.Left)
+
+	var endJump int
+	if e.Operator == kind.Or {
+		elseJump := c.emitJump(OpJumpIfFalse)
+		endJump = c.emitJump(OpJump)
+		c.patchJump(elseJump)
+	} else {
+		endJump = c.emitJump(OpJumpIfFalse)
+	}
+
+	c.emit(OpPop)
+	c.expr(e.Right)
+	c.patchJump(endJump)
+
+	return nil
+}
+
+func (c *Compiler) VisitCallExpr(e internal.Call) any {
+	c.expr(e.Callee)
+	for _, a := range e.Arguments {
+		c.expr(a)
+	}
+	if len(e.Arguments) > math.MaxUint8 {
+		c.error("can't have more than 255 arguments")
+	}
+
+	c.span = Span{Position: e.Position, Near: nearCall(e.Callee)}
+	c.emitU8(OpCall, byte(len(e.Arguments)))
+
+	return nil
+}
+
+func (c *Compiler) VisitGetExpr(e internal.GetExpr) any {
+	c.expr(e.Expression)
+	c.span = Span{Position: e.Position, Near: e.Name}
+	c.emitU16(OpGetProperty, c.identifier(e.Name))
+
+	return nil
+}
+
+func (c *Compiler) VisitSetExpr(e internal.SetExpr) any {
+	c.expr(e.Object)
+	c.expr(e.Value)
+	c.span = Span{Position: e.Position, Near: e.Name}
+	c.emitU16(OpSetProperty, c.identifier(e.Name))
+
+	return nil
+}
+
+func (c *Compiler) VisitThisExpr(e internal.This) any {
+	c.span = Span{Position: e.Position, Near: "this"}
+	c.getVariable("this")
+
+	return nil
+}
+
+func (c *Compiler) VisitSuperExpr(e internal.Super) any {
+	c.span = Span{Position: e.Position, Near: "super." + e.Method}
+	c.getVariable("this")
+	c.getVariable("super")
+	c.emitU16(OpGetSuper, c.identifier(e.Method))
+
+	return nil
+}
+
+func (c *Compiler) VisitListExpr(e internal.ListExpr) any {
+	for _, el := range e.Elements {
+		c.expr(el)
+	}
+	if len(e.Elements) > math.MaxUint16 {
+		c.error("too many elements in list literal")
+	}
+	c.span = Span{Position: e.Position, Near: "["}
+	c.emitU16(OpList, uint16(len(e.Elements)))
+
+	return nil
+}
+
+func (c *Compiler) VisitMapExpr(e internal.MapExpr) any {
+	for idx := range e.Keys {
+		c.expr(e.Keys[idx])
+		c.expr(e.Values[idx])
+	}
+	if len(e.Keys) > math.MaxUint16 {
+		c.error("too many entries in map literal")
+	}
+	c.span = Span{Position: e.Position, Near: "{"}
+	c.emitU16(OpMap, uint16(len(e.Keys)))
+
+	return nil
+}
+
+func (c *Compiler) VisitIndexExpr(e internal.Index) any {
+	c.expr(e.Object)
+	c.expr(e.Index)
+	c.span = Span{Position: e.Position, Near: "["}
+	c.emit(OpIndex)
+
+	return nil
+}
+
+func (c *Compiler) VisitSliceExpr(e internal.Slice) any {
+	c.expr(e.Object)
+	for _, bound := range []internal.Expr{e.Low, e.High} {
+		if bound != nil {
+			c.expr(bound)
+		} else {
+			c.emit(OpNil)
+		}
+	}
+	c.span = Span{Position: e.Position, Near: "["}
+	c.emit(OpSlice)
+
+	return nil
+}
+
+func (c *Compiler) VisitIndexSetExpr(e internal.IndexSet) any {
+	c.expr(e.Object)
+	c.expr(e.Index)
+	c.expr(e.Value)
+	c.span = Span{Position: e.Position, Near: "["}
+	c.emit(OpSetIndex)
+
+	return nil
+}
+
+func (c *Compiler) VisitInterpolationExpr(e internal.Interpolation) any {
+	for _, part := range e.Parts {
+		c.expr(part)
+	}
+	if len(e.Parts) > math.MaxUint16 {
+		c.error("too many parts in string")
+	}
+	c.span = Span{Position: e.Position, Near: "\""}
+	c.emitU16(OpInterpolate, uint16(len(e.Parts)))
+
+	return nil
+}
+
+func (c *Compiler) VisitFunctionExpr(e internal.FunctionExpr) any {
+	c.span = Span{Position: e.Position}
+	c.function(internal.FuncStmt{Parameters: e.Parameters, Body: e.Body}, "", kindFunction)
+
+	return nil
+}
+
+// nearCall returns the name of the called function if it is known from the source code.
+func nearCall(callee internal.Expr) string {
+	switch e := callee.(type) {
+	case internal.Variable:
+		return e.Name
+	case internal.GetExpr:
+		return e.Name
+	case internal.Super:
+		return "super." + e.Method
+	}
+
+	return "("
+}
+
+// function compiles the body of s and emits the closure of it to the enclosing function.
+func (c *Compiler) function(s internal.FuncStmt, name string, kind functionKind) {
+	c.begin(&Function{Name: name, Parameters: s.Parameters}, kind)
+	c.beginScope()
+	for _, p := range s.Parameters {
+		c.addLocal(p)
+		c.markInitialized()
+	}
+	c.stmt(s.Body)
+	fn, upvalues := c.end()
+
+	if len(c.chunk().Functions) > math.MaxUint16 {
+		c.error("too many functions in one chunk")
+	}
+	c.emitU16(OpClosure, uint16(len(c.chunk().Functions)))
+	c.chunk().Functions = append(c.chunk().Functions, fn)
+	for _, u := range upvalues {
+		isLocal := byte(0)
+		if u.isLocal {
+			isLocal = 1
+		}
+		c.emit(OpCode(isLocal), OpCode(u.index))
+	}
+}
+
+func (c *Compiler) begin(fn *Function, kind functionKind) {
+	f := &function{enclosing: c.current, fn: fn, kind: kind, names: make(map[string]uint16)}
+	// the first slot holds the callee, it is "this" for methods
+	slot := local{depth: 0}
+	if kind == kindMethod || kind == kindInitializer {
+		slot.name = "this"
+	}
+	f.locals = append(f.locals, slot)
+	c.current = f
+}
+
+func (c *Compiler) end() (*Function, []upvalue) {
+	if c.current.kind == kindInitializer {
+		c.emitU8(OpGetLocal, 0)
+	} else {
+		c.emit(OpNil)
+	}
+	c.emit(OpReturn)
+
+	f := c.current
+	f.fn.Upvalues = len(f.upvalues)
+	c.current = f.enclosing
+
+	return f.fn, f.upvalues
+}
+
+func (c *Compiler) beginScope() {
+	c.current.scopeDepth++
+}
+
+func (c *Compiler) endScope() {
+	c.current.scopeDepth--
+	c.discardLocals(c.current.scopeDepth)
+
+	locals := c.current.locals
+	for len(locals) > 0 && locals[len(locals)-1].depth > c.current.scopeDepth {
+		locals = locals[:len(locals)-1]
+	}
+	c.current.locals = locals
+}
+
+// discardLocals emits instructions which remove locals deeper than depth from the stack.
+func (c *Compiler) discardLocals(depth int) {
+	locals := c.current.locals
+	for i := len(locals) - 1; i >= 0 && locals[i].depth > depth; i-- {
+		if locals[i].captured {
+			c.emit(OpCloseUpvalue)
+		} else {
+			c.emit(OpPop)
+		}
+	}
+}
+
+func (c *Compiler) beginLoop(span Span) *loop {
+	l := &loop{scopeDepth: c.current.scopeDepth, continueTarget: -1, span: span}
+	c.current.loops = append(c.current.loops, l)
+
+	return l
+}
+
+func (c *Compiler) endLoop() {
+	loops := c.current.loops
+	for _, j := range loops[len(loops)-1].breaks {
+		c.patchJump(j)
+	}
+	c.current.loops = loops[:len(loops)-1]
+}
+
+// loopTries returns the index of the outermost try statement inside the innermost loop.
+func (c *Compiler) loopTries() int {
+	idx := len(c.current.tries)
+	for idx > 0 && c.current.tries[idx-1].loops >= len(c.current.loops) {
+		idx--
+	}
+
+	return idx
+}
+
+// exitTries emits the code which leaves try statements starting with the index from the innermost one:
+// their handlers are removed and their finally clauses are executed.
+func (c *Compiler) exitTries(from int) {
+	tries := c.current.tries
+	for idx := len(tries) - 1; idx >= from; idx-- {
+		t := tries[idx]
+		for n := 0; n < t.handlers; n++ {
+			c.emit(OpPopHandler)
+		}
+		if t.finally == nil {
+			continue
+		}
+
+		// the finally clause must not see locals which are declared inside the try statement
+		names := make([]string, len(c.current.locals)-t.locals)
+		for i := range names {
+			names[i], c.current.locals[t.locals+i].name = c.current.locals[t.locals+i].name, ""
+		}
+		c.current.tries = tries[:idx]
+		c.stmt(t.finally)
+		for i := range names {
+			c.current.locals[t.locals+i].name = names[i]
+		}
+	}
+	c.current.tries = tries
+}
+
+// declare adds a local variable; variables of the top-level scope are globals and they are not declared.
+func (c *Compiler) declare(name string) {
+	if c.current.scopeDepth == 0 {
+		return
+	}
+
+	c.addLocal(name)
+}
+
+// define finishes the declaration of the variable which value is on the top of the stack.
+func (c *Compiler) define(name string) {
+	if c.current.scopeDepth > 0 {
+		c.markInitialized()
+		return
+	}
+
+	c.emitU16(OpDefineGlobal, c.identifier(name))
+}
+
+func (c *Compiler) addLocal(name string) {
+	if len(c.current.locals) > math.MaxUint8 {
+		c.error("too many local variables in function")
+		return
+	}
+
+	c.current.locals = append(c.current.locals, local{name: name, depth: -1})
+}
+
+func (c *Compiler) markInitialized() {
+	if c.current.scopeDepth == 0 {
+		return
+	}
+
+	c.current.locals[len(c.current.locals)-1].depth = c.current.scopeDepth
+}
+
+func (c *Compiler) slotOf(name string) byte {
+	return byte(resolveLocal(c.current, name))
+}
+
+func (c *Compiler) getVariable(name string) {
+	if slot := resolveLocal(c.current, name); slot != -1 {
+		c.emitU8(OpGetLocal, byte(slot))
+	} else if idx := c.resolveUpvalue(c.current, name); idx != -1 {
+		c.emitU8(OpGetUpvalue, byte(idx))
+	} else {
+		c.emitU16(OpGetGlobal, c.identifier(name))
+	}
+}
+
+func (c *Compiler) setVariable(name string) {
+	if slot := resolveLocal(c.current, name); slot != -1 {
+		c.emitU8(OpSetLocal, byte(slot))
+	} else if idx := c.resolveUpvalue(c.current, name); idx != -1 {
+		c.emitU8(OpSetUpvalue, byte(idx))
+	} else {
+		c.emitU16(OpSetGlobal, c.identifier(name))
+	}
+}
+
+func resolveLocal(f *function, name string) int {
+	for i := len(f.locals) - 1; i >= 0; i-- {
+		if f.locals[i].name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *Compiler) resolveUpvalue(f *function, name string) int {
+	if f.enclosing == nil {
+		return -1
+	}
+
+	if slot := resolveLocal(f.enclosing, name); slot != -1 {
+		f.enclosing.locals[slot].captured = true
+		return c.addUpvalue(f, byte(slot), true)
+	}
+
+	if idx := c.resolveUpvalue(f.enclosing, name); idx != -1 {
+		return c.addUpvalue(f, byte(idx), false)
+	}
+
+	return -1
+}
+
+func (c *Compiler) addUpvalue(f *function, index byte, isLocal bool) int {
+	for i, u := range f.upvalues {
+		if u.index == index && u.isLocal == isLocal {
+			return i
+		}
+	}
+
+	if len(f.upvalues) > math.MaxUint8 {
+		c.error("too many closure variables in function")
+		return 0
+	}
+
+	f.upvalues = append(f.upvalues, upvalue{index: index, isLocal: isLocal})
+	return len(f.upvalues) - 1
+}
+
+func (c *Compiler) chunk() *Chunk {
+	return &c.current.fn.Chunk
+}
+
+func (c *Compiler) emit(ops ...OpCode) {
+	chunk := c.chunk()
+	for _, op := range ops {
+		chunk.Code = append(chunk.Code, byte(op))
+		chunk.Spans = append(chunk.Spans, c.span)
+	}
+}
+
+func (c *Compiler) emitU8(op OpCode, operand byte) {
+	c.emit(op, OpCode(operand))
+}
+
+func (c *Compiler) emitU16(op OpCode, operand uint16) {
+	c.emit(op, OpCode(operand>>8), OpCode(operand))
+}
+
+// emitJump emits the jump with a placeholder offset and returns the position of the offset.
+func (c *Compiler) emitJump(op OpCode) int {
+	c.emitU16(op, math.MaxUint16)
+	return len(c.chunk().Code) - 2
+}
+
+// patchJump sets the offset of the jump to the current end of the code.
+func (c *Compiler) patchJump(at int) {
+	code := c.chunk().Code
+	offset := len(code) - at - 2
+	if offset > math.MaxUint16 {
+		c.error("too much code to jump over")
+	}
+
+	code[at] = byte(offset >> 8)
+	code[at+1] = byte(offset)
+}
+
+// emitLoop emits the jump back to start of the innermost loop; the jump is a step of the budget.
+func (c *Compiler) emitLoop(start int) {
+	loops := c.current.loops
+	c.span = loops[len(loops)-1].span
+	offset := len(c.chunk().Code) - start + 3
+	if offset > math.MaxUint16 {
+		c.error("loop body is too large")
+	}
+
+	c.emitU16(OpLoop, uint16(offset))
+}
+
+func (c *Compiler) constant(l internal.Literal) uint16 {
+	chunk := c.chunk()
+	if len(chunk.Constants) > math.MaxUint16 {
+		c.error("too many constants in one chunk")
+		return 0
+	}
+
+	chunk.Constants = append(chunk.Constants, l)
+	return uint16(len(chunk.Constants) - 1)
+}
+
+// identifier returns the constant with the name; each name is stored once per function.
+func (c *Compiler) identifier(name string) uint16 {
+	if idx, ok := c.current.names[name]; ok {
+		return idx
+	}
+
+	idx := c.constant(internal.NewLiteralString(name))
+	c.current.names[name] = idx
+
+	return idx
+}
+
+func (c *Compiler) error(message string) {
+	if c.err == nil {
+		c.err = errors.New(message)
+	}
+}
+
+func (c *Compiler) stmt(s internal.Stmt) {
+	s.Accept(c)
+}
+
+func (c *Compiler) expr(e internal.Expr) {
+	e.Accept(c)
+}
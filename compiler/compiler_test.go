@@ -0,0 +1,75 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/resolver"
+	"github.com/nikgalushko/gan-ilox/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func compile(t *testing.T, code string) (*Function, error) {
+	t.Helper()
+
+	tokens, err := scanner.NewScanner(code).ScanTokens()
+	require.NoError(t, err)
+
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+
+	stmts, err = resolver.New().Resolve(stmts)
+	require.NoError(t, err)
+
+	return New().Compile(stmts)
+}
+
+func TestCompiler_Functions(t *testing.T) {
+	script, err := compile(t, `fun outer(a) { var b = 1; fun inner() { return a + b; } return inner; }`)
+	require.NoError(t, err)
+	require.Len(t, script.Chunk.Spans, len(script.Chunk.Code))
+	require.Len(t, script.Chunk.Functions, 1)
+
+	outer := script.Chunk.Functions[0]
+	require.Equal(t, "outer", outer.Name)
+	require.Equal(t, []string{"a"}, outer.Parameters)
+	require.Zero(t, outer.Upvalues)
+	require.Len(t, outer.Chunk.Functions, 1)
+
+	inner := outer.Chunk.Functions[0]
+	require.Equal(t, "inner", inner.Name)
+	require.Equal(t, 2, inner.Upvalues)
+}
+
+func TestCompiler_Errors(t *testing.T) {
+	var locals strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&locals, "var v%d = %d;", i, i)
+	}
+
+	args := strings.TrimSuffix(strings.Repeat("1, ", 256), ", ")
+
+	var methods strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&methods, "m%d() {}", i)
+	}
+
+	tests := []struct {
+		name string
+		code string
+		err  string
+	}{
+		{name: "locals", code: "fun f() {" + locals.String() + "}", err: "too many local variables in function"},
+		{name: "arguments", code: "fun f() {} f(" + args + ");", err: "can't have more than 255 arguments"},
+		{name: "methods", code: "class A {" + methods.String() + "}", err: "too many methods in class"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compile(t, tt.code)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
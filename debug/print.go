@@ -42,7 +42,12 @@ func (p AstPrinter) VisitClassStmt(s internal.ClassStmt) any {
 		methods = append(methods, p.VisitFuncStmt(m).(string))
 	}
 
-	return "(class " + s.Name + "(" + strings.Join(methods, "; ") + ")"
+	name := s.Name
+	if s.Superclass != nil {
+		name += " < " + s.Superclass.Accept(p).(string)
+	}
+
+	return "(class " + name + "(" + strings.Join(methods, "; ") + ")"
 }
 
 func (p AstPrinter) VisitGetExpr(e internal.GetExpr) any {
@@ -62,7 +67,9 @@ func (p AstPrinter) VisitForSmt(s internal.ForStmt) any {
 		ret = append(ret, "(initializer", s.Initializer.Accept(p).(string)+")")
 	}
 
-	ret = append(ret, "(condition", s.Condition.Accept(p).(string)+")")
+	if s.Condition != nil {
+		ret = append(ret, "(condition", s.Condition.Accept(p).(string)+")")
+	}
 
 	if s.Step != nil {
 		ret = append(ret, "(step", s.Step.Accept(p).(string)+")")
@@ -74,6 +81,43 @@ func (p AstPrinter) VisitForSmt(s internal.ForStmt) any {
 	return strings.Join(ret, " ")
 }
 
+func (p AstPrinter) VisitWhileStmt(s internal.WhileStmt) any {
+	return "(while " + s.Condition.Accept(p).(string) + " (body " + s.Body.Accept(p).(string) + "))"
+}
+
+func (p AstPrinter) VisitBreakStmt(s internal.BreakStmt) any {
+	return "(break)"
+}
+
+func (p AstPrinter) VisitContinueStmt(s internal.ContinueStmt) any {
+	return "(continue)"
+}
+
+func (p AstPrinter) VisitThrowStmt(s internal.ThrowStmt) any {
+	return p.parenthesize("throw", s.Expression)
+}
+
+func (p AstPrinter) VisitTryStmt(s internal.TryStmt) any {
+	ret := []string{"(try", s.Body.Accept(p).(string)}
+	if s.Catch != nil {
+		ret = append(ret, "(catch "+s.Name, s.Catch.Accept(p).(string)+")")
+	}
+
+	if s.Finally != nil {
+		ret = append(ret, "(finally", s.Finally.Accept(p).(string)+")")
+	}
+
+	return strings.Join(ret, " ") + ")"
+}
+
+func (p AstPrinter) VisitImportStmt(s internal.ImportStmt) any {
+	if s.Name != "" {
+		return fmt.Sprintf("(import %q as %s)", s.Path, s.Name)
+	}
+
+	return fmt.Sprintf("(from %q import %s)", s.Path, strings.Join(s.Names, " "))
+}
+
 func (p AstPrinter) VisitIfStmt(s internal.IfStmt) any {
 	ret := []string{
 		p.parenthesize("if", s.Condition).(string),
@@ -155,6 +199,55 @@ func (p AstPrinter) VisitUnaryExpr(expression internal.Unary) any {
 	return p.parenthesize(expression.Operator.String(), expression.Right)
 }
 
+func (p AstPrinter) VisitThisExpr(e internal.This) any {
+	return "this"
+}
+
+func (p AstPrinter) VisitSuperExpr(e internal.Super) any {
+	return "super." + e.Method
+}
+
+func (p AstPrinter) VisitListExpr(e internal.ListExpr) any {
+	return p.parenthesize("list", e.Elements...)
+}
+
+func (p AstPrinter) VisitMapExpr(e internal.MapExpr) any {
+	var entries []internal.Expr
+	for idx := range e.Keys {
+		entries = append(entries, e.Keys[idx], e.Values[idx])
+	}
+
+	return p.parenthesize("map", entries...)
+}
+
+func (p AstPrinter) VisitIndexExpr(e internal.Index) any {
+	return p.parenthesize("index", e.Object, e.Index)
+}
+
+func (p AstPrinter) VisitSliceExpr(e internal.Slice) any {
+	low, high := "", ""
+	if e.Low != nil {
+		low = e.Low.Accept(p).(string)
+	}
+	if e.High != nil {
+		high = e.High.Accept(p).(string)
+	}
+
+	return "(slice " + e.Object.Accept(p).(string) + " " + low + ":" + high + ")"
+}
+
+func (p AstPrinter) VisitIndexSetExpr(e internal.IndexSet) any {
+	return p.parenthesize("set index", e.Object, e.Index, e.Value)
+}
+
+func (p AstPrinter) VisitInterpolationExpr(e internal.Interpolation) any {
+	return p.parenthesize("interpolation", e.Parts...)
+}
+
+func (p AstPrinter) VisitFunctionExpr(e internal.FunctionExpr) any {
+	return p.VisitFuncStmt(internal.FuncStmt{Parameters: e.Parameters, Body: e.Body})
+}
+
 func (p AstPrinter) VisitCallExpr(e internal.Call) any {
 	return "call"
 }
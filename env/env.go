@@ -24,6 +24,17 @@ func NewWithParent(parent *Environment) *Environment {
 	}
 }
 
+// Parent returns the enclosing environment; it is nil for the outermost one.
+func (e *Environment) Parent() *Environment {
+	return e.parent
+}
+
+// Variables returns variables which are defined in the environment itself.
+// The map is shared with the environment, so later definitions and assignments are visible through it.
+func (e *Environment) Variables() map[string]internal.Literal {
+	return e.variables
+}
+
 func (e *Environment) Get(name string) (internal.Literal, error) {
 	v, ok := e.variables[name]
 	if ok {
@@ -52,3 +63,31 @@ func (e *Environment) Assign(name string, value internal.Literal) error {
 
 	return e.parent.Assign(name, value)
 }
+
+func (e *Environment) GetAt(depth int, name string) (internal.Literal, error) {
+	v, ok := e.ancestor(depth).variables[name]
+	if !ok {
+		return internal.LiteralNil, ErrUndefinedVariable
+	}
+
+	return v, nil
+}
+
+func (e *Environment) AssignAt(depth int, name string, value internal.Literal) error {
+	a := e.ancestor(depth)
+	if _, ok := a.variables[name]; !ok {
+		return ErrUndefinedVariable
+	}
+
+	a.variables[name] = value
+	return nil
+}
+
+func (e *Environment) ancestor(depth int) *Environment {
+	ret := e
+	for i := 0; i < depth; i++ {
+		ret = ret.parent
+	}
+
+	return ret
+}
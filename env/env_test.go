@@ -56,3 +56,53 @@ func TestEnvironment(t *testing.T) {
 	require.ErrorIs(t, ErrUndefinedVariable, err)
 	require.Equal(t, internal.LiteralNil, v)
 }
+
+func TestEnvironment_At(t *testing.T) {
+	global := New()
+	global.Define("a", internal.NewLiteralInt(1))
+
+	block := NewWithParent(global)
+	block.Define("a", internal.NewLiteralInt(2))
+
+	inner := NewWithParent(block)
+
+	v, err := inner.GetAt(1, "a")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), v.AsInt())
+
+	v, err = inner.GetAt(2, "a")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), v.AsInt())
+
+	_, err = inner.GetAt(0, "a")
+	require.ErrorIs(t, ErrUndefinedVariable, err)
+
+	err = inner.AssignAt(2, "a", internal.NewLiteralInt(3))
+	require.NoError(t, err)
+
+	v, err = global.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), v.AsInt())
+
+	v, err = block.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), v.AsInt())
+
+	err = inner.AssignAt(0, "a", internal.NewLiteralInt(4))
+	require.ErrorIs(t, ErrUndefinedVariable, err)
+}
+
+func TestEnvironment_Variables(t *testing.T) {
+	global := New()
+	global.Define("a", internal.NewLiteralInt(1))
+
+	module := NewWithParent(global)
+	module.Define("b", internal.NewLiteralInt(2))
+
+	variables := module.Variables()
+	require.Equal(t, map[string]internal.Literal{"b": internal.NewLiteralInt(2)}, variables)
+	require.NoError(t, module.Assign("b", internal.NewLiteralInt(3)))
+	require.Equal(t, internal.NewLiteralInt(3), variables["b"])
+	require.Equal(t, global, module.Parent())
+	require.Nil(t, global.Parent())
+}
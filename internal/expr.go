@@ -4,6 +4,12 @@ import (
 	"github.com/nikgalushko/gan-ilox/token/kind"
 )
 
+// Position is a place of a token in the source code.
+type Position struct {
+	Line   int
+	Column int
+}
+
 type Expr interface {
 	Accept(visitor ExprVisitor) any
 }
@@ -19,9 +25,19 @@ type ExprVisitor interface {
 	VisitCallExpr(e Call) any
 	VisitGetExpr(e GetExpr) any
 	VisitSetExpr(e SetExpr) any
+	VisitThisExpr(e This) any
+	VisitSuperExpr(e Super) any
+	VisitListExpr(e ListExpr) any
+	VisitMapExpr(e MapExpr) any
+	VisitIndexExpr(e Index) any
+	VisitSliceExpr(e Slice) any
+	VisitIndexSetExpr(e IndexSet) any
+	VisitInterpolationExpr(e Interpolation) any
+	VisitFunctionExpr(e FunctionExpr) any
 }
 
 type Call struct {
+	Position
 	Arguments []Expr
 	Callee    Expr
 }
@@ -31,6 +47,7 @@ func (e Call) Accept(v ExprVisitor) any {
 }
 
 type Binary struct {
+	Position
 	Left     Expr
 	Operator kind.TokenType
 	Right    Expr
@@ -57,6 +74,7 @@ func (e LiteralExpr) Accept(visitor ExprVisitor) any {
 }
 
 type Unary struct {
+	Position
 	Operator kind.TokenType
 	Right    Expr
 }
@@ -65,8 +83,15 @@ func (e Unary) Accept(visitor ExprVisitor) any {
 	return visitor.VisitUnaryExpr(e)
 }
 
+// GlobalDepth is a depth of variables which are not declared in any local scope.
+const GlobalDepth = -1
+
 type Variable struct {
+	Position
 	Name string
+	// Depth is a number of scopes between the usage of the variable and its declaration.
+	// It is set by resolver.
+	Depth int
 }
 
 func (e Variable) Accept(visitor ExprVisitor) any {
@@ -74,8 +99,10 @@ func (e Variable) Accept(visitor ExprVisitor) any {
 }
 
 type Assignment struct {
+	Position
 	Name       string
 	Expression Expr
+	Depth      int
 }
 
 func (e Assignment) Accept(visitor ExprVisitor) any {
@@ -93,6 +120,7 @@ func (e Logical) Accept(v ExprVisitor) any {
 }
 
 type GetExpr struct {
+	Position
 	Name       string
 	Expression Expr
 }
@@ -102,6 +130,7 @@ func (e GetExpr) Accept(v ExprVisitor) any {
 }
 
 type SetExpr struct {
+	Position
 	Name   string
 	Object Expr
 	Value  Expr
@@ -110,3 +139,97 @@ type SetExpr struct {
 func (e SetExpr) Accept(v ExprVisitor) any {
 	return v.VisitSetExpr(e)
 }
+
+type This struct {
+	Position
+	Depth int
+}
+
+func (e This) Accept(v ExprVisitor) any {
+	return v.VisitThisExpr(e)
+}
+
+type Super struct {
+	Position
+	Method string
+	Depth  int
+}
+
+func (e Super) Accept(v ExprVisitor) any {
+	return v.VisitSuperExpr(e)
+}
+
+type ListExpr struct {
+	Position
+	Elements []Expr
+}
+
+func (e ListExpr) Accept(v ExprVisitor) any {
+	return v.VisitListExpr(e)
+}
+
+// MapExpr is a map literal; Keys[i] is a key of Values[i].
+type MapExpr struct {
+	Position
+	Keys   []Expr
+	Values []Expr
+}
+
+func (e MapExpr) Accept(v ExprVisitor) any {
+	return v.VisitMapExpr(e)
+}
+
+type Index struct {
+	Position
+	Object Expr
+	Index  Expr
+}
+
+func (e Index) Accept(v ExprVisitor) any {
+	return v.VisitIndexExpr(e)
+}
+
+// Slice is `object[low:high]`; Low and High are nil when they are omitted.
+type Slice struct {
+	Position
+	Object Expr
+	Low    Expr
+	High   Expr
+}
+
+func (e Slice) Accept(v ExprVisitor) any {
+	return v.VisitSliceExpr(e)
+}
+
+type IndexSet struct {
+	Position
+	Object Expr
+	Index  Expr
+	Value  Expr
+}
+
+func (e IndexSet) Accept(v ExprVisitor) any {
+	return v.VisitIndexSetExpr(e)
+}
+
+// Interpolation is a string with embedded expressions; its value is the concatenation
+// of string values of Parts.
+type Interpolation struct {
+	Position
+	Parts []Expr
+}
+
+func (e Interpolation) Accept(v ExprVisitor) any {
+	return v.VisitInterpolationExpr(e)
+}
+
+// FunctionExpr is an anonymous function; its value is a closure.
+type FunctionExpr struct {
+	Position
+	Parameters []string
+	Body       Stmt
+}
+
+func (e FunctionExpr) Accept(v ExprVisitor) any {
+	return v.VisitFunctionExpr(e)
+}
@@ -2,13 +2,39 @@ package internal
 
 import (
 	"errors"
+	"io"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Interpreter interface {
 	Exec(Stmt) (any, error)
 }
 
+// Runtime is the state of the execution which calls a native function. It is implemented
+// by interpreter.Runtime and declared here to avoid the import cycle.
+type Runtime interface {
+	// Sleep waits for d unless the execution is canceled or runs out of its budget before.
+	Sleep(d time.Duration) error
+	// MemoryUsed returns bytes which are allocated by the execution.
+	MemoryUsed() int64
+	// CheckMemory fails when n more bytes exceed the memory limit of the execution; it does not count them.
+	CheckMemory(n int64) error
+	// Stdin returns the input stream of the execution.
+	Stdin() io.Reader
+}
+
+// Environment is a scope of variables. It is implemented by env.Environment
+// and declared here to avoid the import cycle.
+type Environment interface {
+	Get(name string) (Literal, error)
+	Define(name string, value Literal)
+	Assign(name string, value Literal) error
+}
+
 type literalType int8
 
 const (
@@ -21,18 +47,23 @@ const (
 	literalFunction
 	literalClass
 	literalClassInstance
+	literalList
+	literalMap
+	literalBigInt
 )
 
 type Literal struct {
-	i              int64
-	f              float64
-	s              string
-	b              bool
-	function       Function
-	class          Class
-	instance       ClassInstance
-	_type          literalType
-	isReturnResult bool
+	i        int64
+	f        float64
+	s        string
+	b        bool
+	function Function
+	class    Class
+	instance ClassInstance
+	list     *List
+	dict     *Map
+	bigint   *big.Int
+	_type    literalType
 }
 
 type ClassInstance struct {
@@ -47,44 +78,83 @@ func (c ClassInstance) Set(name string, value Literal) {
 func (c ClassInstance) Get(name string) (Literal, error) {
 	v, ok := c.Fields[name]
 	if !ok {
-		v, ok = c.Class.Methods[name]
-		if !ok {
-			return LiteralNil, errors.New("undefined field: " + name)
-		}
+		return LiteralNil, errors.New("undefined field: " + name)
 	}
 
 	return v, nil
 }
 
+// List is a mutable sequence of values. All copies of a list literal share the same elements.
+type List struct {
+	Elements []Literal
+}
+
 type Function struct {
+	// Name is used in stack traces of runtime errors.
+	Name          string
 	ArgumentsName []string
-	body          Stmt
-	f             func(args ...Literal) (Literal, error)
+	// Closure is the environment that was active when the function was declared.
+	// It is nil for native functions.
+	Closure Environment
+	// IsInitializer is true for "init" method of a class; it always returns "this".
+	IsInitializer bool
+	// Compiled is a function of the bytecode backend; it is nil for functions of the tree-walking interpreter.
+	Compiled any
+	body     Stmt
+	f        func(args ...Literal) (Literal, error)
+	// rf is a native function which uses the runtime of the caller.
+	rf func(rt Runtime, args ...Literal) (Literal, error)
+}
+
+// IsNative reports whether the function is implemented in Go.
+func (f Function) IsNative() bool {
+	return f.f != nil || f.rf != nil
+}
+
+func (f Function) String() string {
+	prefix := "<fn"
+	if f.IsNative() {
+		prefix = "<native fn"
+	}
+	if f.Name == "" {
+		return prefix + ">"
+	}
+	return prefix + " " + f.Name + ">"
 }
 
 func (f Function) Call(params []Literal, i Interpreter) (any, error) {
-	if f.f != nil {
-		return f.f(params...)
+	if f.IsNative() {
+		return f.CallNative(nil, params)
 	}
 
 	return i.Exec(f.body)
 }
 
+// CallNative calls the native function by the runtime rt; rt may be nil for functions
+// which are not created by NewLiteralRuntimeFunction.
+func (f Function) CallNative(rt Runtime, params []Literal) (Literal, error) {
+	if f.rf != nil {
+		return f.rf(rt, params...)
+	}
+
+	return f.f(params...)
+}
+
 type Class struct {
 	Name        string
+	Superclass  *Class
 	Initializer *Literal
 	Methods     map[string]Literal
 }
 
-func (c Class) Call(params []Literal, i Interpreter) (any, error) {
-	if c.Initializer != nil {
-		_, err := i.Exec(c.Initializer.function.body)
-		if err != nil {
-			return literalNil, err
-		}
+// FindMethod looks for the method in the class and then in its superclasses.
+func (c Class) FindMethod(name string) (Literal, bool) {
+	m, ok := c.Methods[name]
+	if !ok && c.Superclass != nil {
+		return c.Superclass.FindMethod(name)
 	}
 
-	return Literal{_type: literalClassInstance, instance: ClassInstance{Class: &c, Fields: map[string]Literal{}}}, nil
+	return m, ok
 }
 
 var LiteralNil = Literal{_type: literalNil}
@@ -97,6 +167,16 @@ func NewLiteralFloat(f float64) Literal {
 	return Literal{f: f, _type: literalFloat}
 }
 
+// NewLiteralBigInt returns the integer which is stored as a bignum only if it does not fit into int64,
+// so equal integers always have the same representation. b must not be modified after the call.
+func NewLiteralBigInt(b *big.Int) Literal {
+	if b.IsInt64() {
+		return NewLiteralInt(b.Int64())
+	}
+
+	return Literal{bigint: b, _type: literalBigInt}
+}
+
 func NewLiteralBool(b bool) Literal {
 	return Literal{b: b, _type: literalBool}
 }
@@ -105,16 +185,51 @@ func NewLiteralString(s string) Literal {
 	return Literal{s: s, _type: literalString}
 }
 
-func NewLiteralUserFunction(args []string, body Stmt) Literal {
-	return Literal{_type: literalFunction, function: Function{ArgumentsName: args, body: body}}
+func NewLiteralUserFunction(name string, args []string, body Stmt, closure Environment) Literal {
+	return Literal{_type: literalFunction, function: Function{Name: name, ArgumentsName: args, body: body, Closure: closure}}
+}
+
+func NewLiteralNativeFunction(name string, args []string, f func(args ...Literal) (Literal, error)) Literal {
+	return Literal{_type: literalFunction, function: Function{Name: name, ArgumentsName: args, f: f}}
 }
 
-func NewLiteralNativeFunction(args []string, f func(args ...Literal) (Literal, error)) Literal {
-	return Literal{_type: literalFunction, function: Function{ArgumentsName: args, f: f}}
+// NewLiteralRuntimeFunction creates the native function which uses the runtime of the execution which calls it.
+func NewLiteralRuntimeFunction(name string, args []string, f func(rt Runtime, args ...Literal) (Literal, error)) Literal {
+	return Literal{_type: literalFunction, function: Function{Name: name, ArgumentsName: args, rf: f}}
 }
 
-func NewLiteralClass(name string, methods map[string]Literal) Literal {
-	return Literal{_type: literalClass, class: Class{Name: name, Methods: methods}}
+func NewLiteralCompiledFunction(name string, args []string, compiled any) Literal {
+	return Literal{_type: literalFunction, function: Function{Name: name, ArgumentsName: args, Compiled: compiled}}
+}
+
+func NewLiteralClass(name string, superclass *Class, methods map[string]Literal) Literal {
+	c := Class{Name: name, Superclass: superclass, Methods: methods}
+	if init, ok := methods["init"]; ok {
+		init.function.IsInitializer = true
+		methods["init"] = init
+		c.Initializer = &init
+	} else if superclass != nil {
+		c.Initializer = superclass.Initializer
+	}
+
+	return Literal{_type: literalClass, class: c}
+}
+
+func NewLiteralClassInstance(c *Class) Literal {
+	return Literal{_type: literalClassInstance, instance: ClassInstance{Class: c, Fields: map[string]Literal{}}}
+}
+
+// NewLiteralClassInstanceWithFields creates the instance which fields are shared with the caller.
+func NewLiteralClassInstanceWithFields(c *Class, fields map[string]Literal) Literal {
+	return Literal{_type: literalClassInstance, instance: ClassInstance{Class: c, Fields: fields}}
+}
+
+func NewLiteralList(elements []Literal) Literal {
+	return Literal{_type: literalList, list: &List{Elements: elements}}
+}
+
+func NewLiteralMap(m *Map) Literal {
+	return Literal{_type: literalMap, dict: m}
 }
 
 func (l Literal) IsClass() bool {
@@ -129,14 +244,32 @@ func (l Literal) IsFunction() bool {
 	return l._type == literalFunction
 }
 
+func (l Literal) IsList() bool {
+	return l._type == literalList
+}
+
+func (l Literal) IsMap() bool {
+	return l._type == literalMap
+}
+
 func (l Literal) IsNumber() bool {
-	return l.IsInt() || l.IsFloat()
+	return l.IsInteger() || l.IsFloat()
 }
 
+// IsInt reports whether the literal is an integer which fits into int64; see IsInteger.
 func (l Literal) IsInt() bool {
 	return l._type == literalInt
 }
 
+func (l Literal) IsBigInt() bool {
+	return l._type == literalBigInt
+}
+
+// IsInteger reports whether the literal is an int or a bignum.
+func (l Literal) IsInteger() bool {
+	return l.IsInt() || l.IsBigInt()
+}
+
 func (l Literal) IsFloat() bool {
 	return l._type == literalFloat
 }
@@ -153,20 +286,69 @@ func (l Literal) IsNil() bool {
 	return l._type == literalNil
 }
 
-func (l Literal) IsReturnResult() bool {
-	return l.isReturnResult
+// TypeName returns the name of the type of the literal which is used in error messages.
+func (l Literal) TypeName() string {
+	switch {
+	case l.IsNil():
+		return "nil"
+	case l.IsBool():
+		return "bool"
+	case l.IsInt():
+		return "int"
+	case l.IsBigInt():
+		return "bigint"
+	case l.IsFloat():
+		return "float"
+	case l.IsString():
+		return "string"
+	case l.IsList():
+		return "list"
+	case l.IsMap():
+		return "map"
+	case l.IsFunction():
+		return "function"
+	case l.IsClass():
+		return "class"
+	case l.IsClassInstance():
+		return "instance"
+	}
+
+	return "unknown"
 }
 
+// AsInt returns the integer; floats are truncated and bignums are reduced to their low 64 bits.
 func (l Literal) AsInt() int64 {
-	if l._type == literalFloat {
+	switch l._type {
+	case literalFloat:
 		return int64(l.f)
+	case literalBigInt:
+		return l.bigint.Int64()
 	}
 	return l.i
 }
 
+// AsBigInt returns the integer as a bignum; floats are truncated. The result must not be modified.
+func (l Literal) AsBigInt() *big.Int {
+	switch l._type {
+	case literalBigInt:
+		return l.bigint
+	case literalFloat:
+		if math.IsNaN(l.f) || math.IsInf(l.f, 0) {
+			return new(big.Int)
+		}
+		ret, _ := big.NewFloat(l.f).Int(nil)
+		return ret
+	}
+	return big.NewInt(l.i)
+}
+
 func (l Literal) AsFloat() float64 {
-	if l._type == literalInt {
+	switch l._type {
+	case literalInt:
 		return float64(l.i)
+	case literalBigInt:
+		ret, _ := new(big.Float).SetInt(l.bigint).Float64()
+		return ret
 	}
 	return l.f
 }
@@ -191,8 +373,9 @@ func (l Literal) AsFunction() Function {
 	return l.function
 }
 
-func (l Literal) AsReturnResult() Literal {
-	l.isReturnResult = true
+// WithClosure returns a copy of the function literal which is bound to closure.
+func (l Literal) WithClosure(closure Environment) Literal {
+	l.function.Closure = closure
 	return l
 }
 
@@ -203,16 +386,69 @@ func (l Literal) AsClass() Class {
 func (l Literal) AsClassInstance() ClassInstance {
 	return l.instance
 }
+
+func (l Literal) AsList() *List {
+	return l.list
+}
+
+func (l Literal) AsMap() *Map {
+	return l.dict
+}
+
 func (l Literal) String() string {
+	return l.format(nil)
+}
+
+// format returns the string of the literal; printing holds collections which are being printed around it,
+// so a collection which contains itself is printed as [...] or {...} instead of recursing forever.
+func (l Literal) format(printing map[any]bool) string {
 	var ret string
 	if l.IsInt() {
 		ret = strconv.FormatInt(l.i, 10)
+	} else if l.IsBigInt() {
+		ret = l.bigint.String()
 	} else if l.IsFloat() {
-		ret = strconv.FormatFloat(l.f, 'e', 10, 64)
+		ret = strconv.FormatFloat(l.f, 'g', -1, 64)
 	} else if l.IsBool() {
 		ret = strconv.FormatBool(l.b)
 	} else if l.IsString() {
 		ret = l.s
+	} else if l.IsList() {
+		if printing[l.list] {
+			return "[...]"
+		}
+		if printing == nil {
+			printing = make(map[any]bool)
+		}
+		printing[l.list] = true
+		defer delete(printing, l.list)
+
+		elements := make([]string, 0, len(l.list.Elements))
+		for _, e := range l.list.Elements {
+			elements = append(elements, e.format(printing))
+		}
+		ret = "[" + strings.Join(elements, ", ") + "]"
+	} else if l.IsMap() {
+		if printing[l.dict] {
+			return "{...}"
+		}
+		if printing == nil {
+			printing = make(map[any]bool)
+		}
+		printing[l.dict] = true
+		defer delete(printing, l.dict)
+
+		entries := make([]string, 0, l.dict.Len())
+		for idx, k := range l.dict.keys {
+			entries = append(entries, k.format(printing)+": "+l.dict.values[idx].format(printing))
+		}
+		ret = "{" + strings.Join(entries, ", ") + "}"
+	} else if l.IsFunction() {
+		ret = l.function.String()
+	} else if l.IsClass() {
+		ret = "<class " + l.class.Name + ">"
+	} else if l.IsClassInstance() {
+		ret = "<" + l.instance.Class.Name + " instance>"
 	} else {
 		ret = "nil"
 	}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLiteralBigInt(t *testing.T) {
+	small := NewLiteralBigInt(big.NewInt(-5))
+	require.True(t, small.IsInt())
+	require.Equal(t, NewLiteralInt(-5), small)
+
+	huge := NewLiteralBigInt(new(big.Int).Lsh(big.NewInt(-1), 64))
+	require.True(t, huge.IsBigInt())
+	require.True(t, huge.IsInteger())
+	require.True(t, huge.IsNumber())
+	require.Equal(t, "-18446744073709551616", huge.String())
+	require.Equal(t, -math.Ldexp(1, 64), huge.AsFloat())
+	require.Equal(t, "3", NewLiteralFloat(3.9).AsBigInt().String())
+}
+
+func TestLiteral_StringOfFloat(t *testing.T) {
+	tests := map[float64]string{
+		1.5:          "1.5",
+		0.1:          "0.1",
+		2:            "2",
+		-0.25:        "-0.25",
+		6.02e23:      "6.02e+23",
+		1e-7:         "1e-07",
+		math.Inf(-1): "-Inf",
+	}
+
+	for f, expected := range tests {
+		require.Equal(t, expected, NewLiteralFloat(f).String())
+	}
+}
+
+func TestLiteral_StringOfCycle(t *testing.T) {
+	a := NewLiteralList([]Literal{NewLiteralInt(1)})
+	a.AsList().Elements = append(a.AsList().Elements, a)
+	require.Equal(t, "[1, [...]]", a.String())
+
+	b := NewLiteralList([]Literal{a, a})
+	require.Equal(t, "[[1, [...]], [1, [...]]]", b.String())
+}
+
+func TestLiteral_StringOfMapCycle(t *testing.T) {
+	m := NewLiteralMap(NewMap())
+	require.NoError(t, m.AsMap().Set(NewLiteralString("self"), m))
+	require.Equal(t, "{self: {...}}", m.String())
+
+	l := NewLiteralList([]Literal{m})
+	require.NoError(t, m.AsMap().Set(NewLiteralString("list"), l))
+	require.Equal(t, "[{self: {...}, list: [...]}]", l.String())
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrInvalidMapKey = errors.New("map key must be a string, a number or a bool")
+
+// mapKey is a comparable representation of a literal which is used as a key of a map.
+// Integral floats are stored as integers, so 1 and 1.0 are the same key as they are equal in Lox.
+// Bignums are stored as their decimal representation in s.
+type mapKey struct {
+	_type literalType
+	i     int64
+	f     float64
+	s     string
+	b     bool
+}
+
+func newMapKey(l Literal) (mapKey, error) {
+	switch l._type {
+	case literalInt:
+		return mapKey{_type: literalInt, i: l.i}, nil
+	case literalFloat:
+		if l.f == math.Trunc(l.f) && l.f >= math.MinInt64 && l.f < math.MaxInt64 {
+			return mapKey{_type: literalInt, i: int64(l.f)}, nil
+		}
+		if l.f == math.Trunc(l.f) && !math.IsInf(l.f, 0) {
+			return mapKey{_type: literalBigInt, s: l.AsBigInt().String()}, nil
+		}
+		return mapKey{_type: literalFloat, f: l.f}, nil
+	case literalBigInt:
+		return mapKey{_type: literalBigInt, s: l.bigint.String()}, nil
+	case literalString:
+		return mapKey{_type: literalString, s: l.s}, nil
+	case literalBool:
+		return mapKey{_type: literalBool, b: l.b}, nil
+	}
+
+	return mapKey{}, ErrInvalidMapKey
+}
+
+// Map is a mutable dictionary which keeps keys in the order of insertion.
+// All copies of a map literal share the same entries.
+type Map struct {
+	index  map[mapKey]int
+	keys   []Literal
+	values []Literal
+}
+
+func NewMap() *Map {
+	return &Map{index: make(map[mapKey]int)}
+}
+
+func (m *Map) Get(key Literal) (Literal, error) {
+	k, err := newMapKey(key)
+	if err != nil {
+		return LiteralNil, err
+	}
+
+	idx, ok := m.index[k]
+	if !ok {
+		return LiteralNil, errors.New("undefined key: " + key.String())
+	}
+
+	return m.values[idx], nil
+}
+
+func (m *Map) Set(key, value Literal) error {
+	k, err := newMapKey(key)
+	if err != nil {
+		return err
+	}
+
+	if idx, ok := m.index[k]; ok {
+		m.values[idx] = value
+		return nil
+	}
+
+	m.index[k] = len(m.keys)
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+
+	return nil
+}
+
+func (m *Map) Has(key Literal) (bool, error) {
+	k, err := newMapKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := m.index[k]
+	return ok, nil
+}
+
+// Delete removes the key from the map and reports whether the key was present.
+func (m *Map) Delete(key Literal) (bool, error) {
+	k, err := newMapKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	idx, ok := m.index[k]
+	if !ok {
+		return false, nil
+	}
+
+	delete(m.index, k)
+	m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
+	m.values = append(m.values[:idx], m.values[idx+1:]...)
+	for k, i := range m.index {
+		if i > idx {
+			m.index[k] = i - 1
+		}
+	}
+
+	return true, nil
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns a copy of keys in the order of insertion.
+func (m *Map) Keys() []Literal {
+	return append([]Literal{}, m.keys...)
+}
+
+// Values returns a copy of values in the order of insertion of their keys.
+func (m *Map) Values() []Literal {
+	return append([]Literal{}, m.values...)
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap(t *testing.T) {
+	m := NewMap()
+	require.NoError(t, m.Set(NewLiteralString("a"), NewLiteralInt(1)))
+	require.NoError(t, m.Set(NewLiteralInt(2), NewLiteralInt(2)))
+	require.NoError(t, m.Set(NewLiteralBool(true), NewLiteralInt(3)))
+	require.NoError(t, m.Set(NewLiteralFloat(2), NewLiteralInt(4)))
+	require.ErrorIs(t, m.Set(LiteralNil, NewLiteralInt(5)), ErrInvalidMapKey)
+
+	require.Equal(t, 3, m.Len())
+	require.Equal(t, []Literal{NewLiteralString("a"), NewLiteralInt(2), NewLiteralBool(true)}, m.Keys())
+	require.Equal(t, []Literal{NewLiteralInt(1), NewLiteralInt(4), NewLiteralInt(3)}, m.Values())
+
+	v, err := m.Get(NewLiteralFloat(2.0))
+	require.NoError(t, err)
+	require.Equal(t, NewLiteralInt(4), v)
+
+	_, err = m.Get(NewLiteralFloat(2.5))
+	require.EqualError(t, err, "undefined key: 2.5")
+
+	ok, err := m.Delete(NewLiteralString("a"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = m.Has(NewLiteralString("a"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	v, err = m.Get(NewLiteralBool(true))
+	require.NoError(t, err)
+	require.Equal(t, NewLiteralInt(3), v)
+	require.Equal(t, "{2: 4, true: 3}", NewLiteralMap(m).String())
+}
+
+func TestMap_BigIntKeys(t *testing.T) {
+	huge := NewLiteralBigInt(new(big.Int).Lsh(big.NewInt(1), 64))
+
+	m := NewMap()
+	require.NoError(t, m.Set(huge, NewLiteralInt(1)))
+	require.NoError(t, m.Set(NewLiteralBigInt(big.NewInt(2)), NewLiteralInt(2)))
+
+	v, err := m.Get(NewLiteralFloat(math.Ldexp(1, 64)))
+	require.NoError(t, err)
+	require.Equal(t, NewLiteralInt(1), v)
+
+	v, err = m.Get(NewLiteralInt(2))
+	require.NoError(t, err)
+	require.Equal(t, NewLiteralInt(2), v)
+	require.Equal(t, "{18446744073709551616: 1, 2: 2}", NewLiteralMap(m).String())
+}
@@ -0,0 +1,96 @@
+package programtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Budget limits the execution of a program; zero fields keep defaults of the backend.
+type Budget struct {
+	MaxSteps     int
+	MaxCallDepth int
+}
+
+// BudgetCase is a program which is run within the budget; Err is empty when the program fits into the budget.
+type BudgetCase struct {
+	Name   string
+	Code   string
+	Budget Budget
+	Err    string
+}
+
+// BudgetRunner executes the code within the budget and returns values of its top-level expression statements.
+type BudgetRunner func(t *testing.T, code string, b Budget) ([]any, error)
+
+// Budgets are programs which are limited by steps and call depth. Every call and every jump back
+// to the start of a loop is a step, so backends stop programs at the same place.
+var Budgets = []BudgetCase{
+	{
+		Name:   "steps of infinite loop",
+		Code:   `for {}`,
+		Budget: Budget{MaxSteps: 100},
+		Err:    "1:1: budget exceeded: more than 100 steps near 'for'",
+	},
+	{
+		Name:   "steps of loop",
+		Code:   `for (var i = 0; i < 10; i = i + 1) {}`,
+		Budget: Budget{MaxSteps: 10},
+	},
+	{
+		Name:   "steps of loop over budget",
+		Code:   `for (var i = 0; i < 10; i = i + 1) {}`,
+		Budget: Budget{MaxSteps: 9},
+		Err:    "1:1: budget exceeded: more than 9 steps near 'for'",
+	},
+	{
+		Name:   "steps of continue",
+		Code:   `var i = 0; while (true) { i = i + 1; if (i > 0) { continue; } }`,
+		Budget: Budget{MaxSteps: 5},
+		Err:    "1:12: budget exceeded: more than 5 steps near 'while'",
+	},
+	{
+		Name:   "steps of calls",
+		Code:   `fun f() {} for (var i = 0; i < 10; i = i + 1) { f(); }`,
+		Budget: Budget{MaxSteps: 15},
+		Err:    "1:12: budget exceeded: more than 15 steps near 'for'",
+	},
+	{
+		Name:   "steps of call",
+		Code:   `fun f() {} for (var i = 0; i < 10; i = i + 1) { f(); } f();`,
+		Budget: Budget{MaxSteps: 20},
+		Err:    "1:57: budget exceeded: more than 20 steps near 'f'",
+	},
+	{
+		Name: "default call depth",
+		Code: `fun f() { f(); } f();`,
+		Err:  "1:12: budget exceeded: call depth exceeds 4096 near 'f'",
+	},
+	{
+		Name:   "call depth",
+		Code:   `fun f(n) { if (n > 0) { f(n - 1); } } f(3);`,
+		Budget: Budget{MaxCallDepth: 3},
+		Err:    "1:26: budget exceeded: call depth exceeds 3 near 'f'",
+	},
+	{
+		Name:   "not catchable",
+		Code:   `var r; try { for {} } catch (e) { r = e; } finally { r = 1; }`,
+		Budget: Budget{MaxSteps: 100},
+		Err:    "1:14: budget exceeded: more than 100 steps near 'for'",
+	},
+}
+
+// RunBudgets runs budget programs by the backend.
+func RunBudgets(t *testing.T, run BudgetRunner) {
+	for _, tt := range Budgets {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := run(t, tt.Code, tt.Budget)
+			if tt.Err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tt.Err)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package programtest
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// OutputRunner executes the code and writes its output to out; uncaught errors are reported to out too.
+type OutputRunner func(t *testing.T, code string, out io.Writer)
+
+// Golden runs each .lox file in the dir and compares its output with the .golden file
+// with the same name, so the output includes the error which stops the script.
+// The flag -update rewrites golden files by the actual output.
+func Golden(t *testing.T, dir string, run OutputRunner) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.lox"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".lox")
+		t.Run(name, func(t *testing.T) {
+			code, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			run(t, string(code), &out)
+
+			golden := strings.TrimSuffix(file, ".lox") + ".golden"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, out.Bytes(), 0o644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), out.String())
+		})
+	}
+}
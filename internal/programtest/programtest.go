@@ -0,0 +1,1126 @@
+// Package programtest contains programs which are run by every backend,
+// so the tree-walking interpreter and the virtual machine have the same semantics.
+package programtest
+
+import (
+	"math/big"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/stretchr/testify/require"
+)
+
+// Case is a program and values of its top-level expression statements.
+type Case struct {
+	Name     string
+	Code     string
+	Expected []any
+}
+
+// ErrorCase is a program which fails with the runtime error.
+type ErrorCase struct {
+	Code string
+	Err  string
+}
+
+type Suite struct {
+	Name   string
+	Cases  []Case
+	Errors []ErrorCase
+}
+
+// Runner executes the code and returns values of its top-level expression statements.
+type Runner func(t *testing.T, code string) ([]any, error)
+
+var Suites = []Suite{
+	{Name: "closure", Cases: closure},
+	{Name: "this", Cases: this},
+	{Name: "constructor", Cases: constructor, Errors: constructorErrors},
+	{Name: "inheritance", Cases: inheritance, Errors: inheritanceErrors},
+	{Name: "loops", Cases: loops},
+	{Name: "runtime errors", Errors: runtimeErrors},
+	{Name: "lists", Cases: lists, Errors: listErrors},
+	{Name: "maps", Cases: maps, Errors: mapErrors},
+	{Name: "memory", Cases: memory},
+	{Name: "operators", Cases: operators, Errors: operatorErrors},
+	{Name: "integers", Cases: integers, Errors: integerErrors},
+	{Name: "interpolation", Cases: interpolation, Errors: interpolationErrors},
+	{Name: "anonymous functions", Cases: anonymousFunctions, Errors: anonymousFunctionErrors},
+	{Name: "exceptions", Cases: exceptions, Errors: exceptionErrors},
+	{Name: "modules", Cases: modules, Errors: moduleErrors},
+	{Name: "strings", Cases: stringMethods, Errors: stringMethodErrors},
+}
+
+// ModulePath is a directory with modules which are imported by programs.
+var ModulePath = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata")
+}()
+
+// Run runs all suites by the backend.
+func Run(t *testing.T, run Runner) {
+	for _, s := range Suites {
+		t.Run(s.Name, func(t *testing.T) {
+			for _, tt := range s.Cases {
+				t.Run(tt.Name, func(t *testing.T) {
+					ret, err := run(t, tt.Code)
+					require.NoError(t, err)
+					require.Equal(t, tt.Expected, ret)
+				})
+			}
+
+			for _, tt := range s.Errors {
+				t.Run(tt.Code, func(t *testing.T) {
+					_, err := run(t, tt.Code)
+					require.EqualError(t, err, tt.Err)
+				})
+			}
+		})
+	}
+}
+
+// Unbounded are programs which allocate memory until they are stopped; they use the builtin push.
+var Unbounded = []string{
+	`var s = "ab"; for { s = s + s; }`,
+	`var xs = []; for { push(xs, xs); }`,
+	`var xs = [1]; for { xs = xs[:]; }`,
+	`var m = {}; var i = 0; for { m[i] = [i]; i = i + 1; }`,
+	`class A {} var xs = []; for { push(xs, A()); }`,
+	`class A {} for { var a = A(); a.field = {}; }`,
+}
+
+// Oversized are programs which build a huge string by a single call; the memory limit of 1 MiB
+// stops them before the string is built. They use the builtin push and the strings module.
+var Oversized = []string{
+	`"ab".repeat(50000000);`,
+	`"a".padLeft(50000000, "ab");`,
+	`"a".padRight(50000000, "ñ");`,
+	`import "strings" as s; s.repeat("ab", 50000000);`,
+	`import "strings" as s; s.padLeft("", 50000000, "ab");`,
+	`var a = "a".repeat(10000); var b = "b".repeat(10000); a.replace("a", b);`,
+	`var s = "x".repeat(100000); var l = []; for (var i = 0; i < 1000; i = i + 1) { push(l, s); } "".join(l);`,
+}
+
+func bigint(s string) internal.Literal {
+	b, _ := new(big.Int).SetString(s, 10)
+	return internal.NewLiteralBigInt(b)
+}
+
+func list(elements ...internal.Literal) internal.Literal {
+	return internal.NewLiteralList(elements)
+}
+
+var (
+	one, two, three = internal.NewLiteralInt(1), internal.NewLiteralInt(2), internal.NewLiteralInt(3)
+
+	closure = []Case{
+		{
+			Name: "counter",
+			Code: `
+			fun makeCounter() {
+				var i = 0;
+				fun count() {
+					i = i + 1;
+					return i;
+				}
+				return count;
+			}
+
+			var c1 = makeCounter();
+			var c2 = makeCounter();
+			c1();
+			c1();
+			c2();
+			c1();
+			`,
+			Expected: []any{
+				internal.NewLiteralInt(1),
+				internal.NewLiteralInt(2),
+				internal.NewLiteralInt(1),
+				internal.NewLiteralInt(3),
+			},
+		},
+		{
+			Name: "adder factory",
+			Code: `
+			fun makeAdder(n) {
+				fun add(x) {
+					return x + n;
+				}
+				return add;
+			}
+
+			var add5 = makeAdder(5);
+			var n = 100;
+			add5(1);
+			`,
+			Expected: []any{internal.NewLiteralInt(6)},
+		},
+		{
+			Name: "callee does not see caller scope",
+			Code: `
+			var a = "global";
+			fun show() {
+				return a;
+			}
+			fun caller() {
+				var a = "local";
+				return show();
+			}
+			caller();
+			`,
+			Expected: []any{internal.NewLiteralString("global")},
+		},
+		{
+			Name: "returned value does not interrupt a block",
+			Code: `
+			fun one() {
+				return 1;
+			}
+			var a = 0;
+			{
+				one();
+				a = 2;
+			}
+			a;
+			`,
+			Expected: []any{internal.NewLiteralInt(2)},
+		},
+	}
+
+	this = []Case{
+		{
+			Name: "method writes and reads own fields",
+			Code: `
+			class Counter {
+				inc() {
+					this.n = this.n + 1;
+					return this.n;
+				}
+			}
+
+			var c = Counter();
+			c.n = 10;
+			c.inc();
+			c.inc();
+			c.n;
+			`,
+			Expected: []any{
+				internal.NewLiteralInt(11),
+				internal.NewLiteralInt(12),
+				internal.NewLiteralInt(12),
+			},
+		},
+		{
+			Name: "method keeps its instance when it is detached",
+			Code: `
+			class Person {
+				name() {
+					return this.n;
+				}
+			}
+
+			var a = Person();
+			a.n = "a";
+			var b = Person();
+			b.n = "b";
+			var f = a.name;
+			b.f = f;
+			b.f();
+			`,
+			Expected: []any{internal.NewLiteralString("a")},
+		},
+		{
+			Name: "closure inside method sees this",
+			Code: `
+			class Box {
+				getter() {
+					fun get() {
+						return this.v;
+					}
+					return get;
+				}
+			}
+
+			var box = Box();
+			box.v = 42;
+			box.getter()();
+			`,
+			Expected: []any{internal.NewLiteralInt(42)},
+		},
+	}
+
+	constructor = []Case{
+		{
+			Name: "init with arguments",
+			Code: `
+			class Point {
+				init(x, y) {
+					this.x = x;
+					this.y = y;
+				}
+
+				sum() {
+					return this.x + this.y;
+				}
+			}
+
+			var p = Point(1, 2);
+			p.x;
+			p.y;
+			p.sum();
+			`,
+			Expected: []any{
+				internal.NewLiteralInt(1),
+				internal.NewLiteralInt(2),
+				internal.NewLiteralInt(3),
+			},
+		},
+		{
+			Name: "early return from init gives the instance",
+			Code: `
+			class Flag {
+				init(v) {
+					this.v = "default";
+					if (v) {
+						return;
+					}
+					this.v = "changed";
+				}
+			}
+
+			Flag(true).v;
+			Flag(false).v;
+			`,
+			Expected: []any{
+				internal.NewLiteralString("default"),
+				internal.NewLiteralString("changed"),
+			},
+		},
+		{
+			Name: "direct call of init returns the instance",
+			Code: `
+			class A {
+				init() {
+					this.n = 1;
+				}
+			}
+
+			var a = A();
+			a.n = 2;
+			var b = a.init();
+			b.n;
+			`,
+			Expected: []any{internal.NewLiteralInt(1)},
+		},
+	}
+
+	inheritance = []Case{
+		{
+			Name: "inherited method",
+			Code: `
+			class A {
+				name() {
+					return "A";
+				}
+			}
+			class B < A {}
+			class C < B {}
+
+			C().name();
+			`,
+			Expected: []any{internal.NewLiteralString("A")},
+		},
+		{
+			Name: "overridden method calls super",
+			Code: `
+			class Animal {
+				speak() {
+					return "...";
+				}
+				describe() {
+					return this.speak();
+				}
+			}
+			class Dog < Animal {
+				speak() {
+					return "woof" + super.speak();
+				}
+			}
+
+			Dog().describe();
+			`,
+			Expected: []any{internal.NewLiteralString("woof...")},
+		},
+		{
+			Name: "inherited and super constructor",
+			Code: `
+			class Shape {
+				init(name) {
+					this.name = name;
+				}
+			}
+			class Square < Shape {
+				init(side) {
+					super.init("square");
+					this.side = side;
+				}
+			}
+			class Unknown < Shape {}
+
+			var s = Square(2);
+			s.name;
+			s.side;
+			Unknown("?").name;
+			`,
+			Expected: []any{
+				internal.NewLiteralString("square"),
+				internal.NewLiteralInt(2),
+				internal.NewLiteralString("?"),
+			},
+		},
+		{
+			Name: "super is resolved statically",
+			Code: `
+			class A {
+				method() {
+					return "A";
+				}
+			}
+			class B < A {
+				method() {
+					return "B";
+				}
+				test() {
+					return super.method();
+				}
+			}
+			class C < B {}
+
+			C().test();
+			`,
+			Expected: []any{internal.NewLiteralString("A")},
+		},
+	}
+
+	loops = []Case{
+		{
+			Name: "while",
+			Code: `
+			var i = 0;
+			while (i < 5) {
+				i = i + 2;
+			}
+			i;
+			`,
+			Expected: []any{internal.NewLiteralInt(6)},
+		},
+		{
+			Name: "break from infinite for",
+			Code: `
+			var i = 0;
+			for {
+				i = i + 1;
+				if (i == 3) {
+					break;
+				}
+			}
+			i;
+			`,
+			Expected: []any{internal.NewLiteralInt(3)},
+		},
+		{
+			Name: "continue runs the step",
+			Code: `
+			var sum = 0;
+			for (var i = 0; i < 10; i = i + 1) {
+				if (i < 5) {
+					continue;
+				}
+				sum = sum + i;
+			}
+			sum;
+			`,
+			Expected: []any{internal.NewLiteralInt(35)},
+		},
+		{
+			Name: "continue in while",
+			Code: `
+			var i = 0;
+			var odd = 0;
+			while (i < 6) {
+				i = i + 1;
+				{
+					if (i == 2 or i == 4 or i == 6) {
+						continue;
+					}
+				}
+				odd = odd + 1;
+			}
+			odd;
+			`,
+			Expected: []any{internal.NewLiteralInt(3)},
+		},
+		{
+			Name: "break leaves only the inner loop",
+			Code: `
+			var count = 0;
+			for (var i = 0; i < 3; i = i + 1) {
+				for (var j = 0; j < 3; j = j + 1) {
+					if (j == 1) {
+						break;
+					}
+					count = count + 1;
+				}
+			}
+			count;
+			`,
+			Expected: []any{internal.NewLiteralInt(3)},
+		},
+		{
+			Name: "return from loop inside function",
+			Code: `
+			fun find(n) {
+				var i = 0;
+				while (true) {
+					if (i * i >= n) {
+						return i;
+					}
+					i = i + 1;
+				}
+			}
+			find(10);
+			`,
+			Expected: []any{internal.NewLiteralInt(4)},
+		},
+	}
+
+	lists = []Case{
+		{
+			Name:     "literal and index",
+			Code:     `var xs = [1, 2, 3]; xs; xs[0]; xs[2];`,
+			Expected: []any{list(one, two, three), one, three},
+		},
+		{
+			Name:     "negative index",
+			Code:     `var xs = [1, 2, 3]; xs[-1]; xs[-3];`,
+			Expected: []any{three, one},
+		},
+		{
+			Name:     "assignment by index is visible through all references",
+			Code:     `var xs = [1, 2]; var ys = xs; ys[-1] = 3; xs;`,
+			Expected: []any{internal.NewLiteralInt(3), list(one, three)},
+		},
+		{
+			Name: "slices",
+			Code: `
+			var xs = [1, 2, 3];
+			xs[1:];
+			xs[:-1];
+			xs[1:1];
+			xs[-2:3];
+			`,
+			Expected: []any{list(two, three), list(one, two), internal.NewLiteralList([]internal.Literal{}), list(two, three)},
+		},
+		{
+			Name:     "slice is a copy",
+			Code:     `var xs = [1, 2]; var ys = xs[:]; ys[0] = 3; xs;`,
+			Expected: []any{internal.NewLiteralInt(3), list(one, two)},
+		},
+		{
+			Name:     "nested lists",
+			Code:     `var m = [[1], [2, 3]]; m[1][0]; m[0][0] = 3; m;`,
+			Expected: []any{two, three, list(list(three), list(two, three))},
+		},
+	}
+
+	maps = []Case{
+		{
+			Name: "literal and index",
+			Code: `var m = {"a": 1, 2: "b", true: 3,}; m["a"]; m[2]; m[true];`,
+			Expected: []any{
+				internal.NewLiteralInt(1),
+				internal.NewLiteralString("b"),
+				internal.NewLiteralInt(3),
+			},
+		},
+		{
+			Name: "integral float and int are the same key",
+			Code: `var m = {1: "int"}; m[1.0]; m[1.0] = "float"; m[1];`,
+			Expected: []any{
+				internal.NewLiteralString("int"),
+				internal.NewLiteralString("float"),
+				internal.NewLiteralString("float"),
+			},
+		},
+		{
+			Name: "assignment is visible through all references",
+			Code: `var m = {}; var n = m; n["k"] = 1; m["k"];`,
+			Expected: []any{
+				internal.NewLiteralInt(1),
+				internal.NewLiteralInt(1),
+			},
+		},
+		{
+			Name:     "map inside a block",
+			Code:     `var v; { v = {"a": [1]}["a"][0]; } v;`,
+			Expected: []any{internal.NewLiteralInt(1)},
+		},
+	}
+
+	memory = []Case{
+		{
+			Name:     "concatenation allocates the result",
+			Code:     `var a = "a"; var before = memoryUsage(); var s = a + "bc"; memoryUsage() - before;`,
+			Expected: []any{three},
+		},
+		{
+			Name:     "numbers do not allocate",
+			Code:     `var before = memoryUsage(); var n = 1 + 2.5; memoryUsage() - before;`,
+			Expected: []any{internal.NewLiteralInt(0)},
+		},
+	}
+
+	operators = []Case{
+		{
+			Name: "integer operators",
+			Code: `7 % 3; -7 % 3; 6 & 3; 6 | 3; 6 ^ 3; 1 << 4; -16 >> 2; 2 ** 10; 2 ** 0;`,
+			Expected: []any{
+				internal.NewLiteralInt(1),
+				internal.NewLiteralInt(-1),
+				internal.NewLiteralInt(2),
+				internal.NewLiteralInt(7),
+				internal.NewLiteralInt(5),
+				internal.NewLiteralInt(16),
+				internal.NewLiteralInt(-4),
+				internal.NewLiteralInt(1024),
+				internal.NewLiteralInt(1),
+			},
+		},
+		{
+			Name: "exponentiation of floats",
+			Code: `2 ** -1; 4.0 ** 0.5;`,
+			Expected: []any{
+				internal.NewLiteralFloat(0.5),
+				internal.NewLiteralFloat(2),
+			},
+		},
+		{
+			Name: "precedence",
+			Code: `1 | 2 ^ 3 & 5 << 1 + 1; 2 + 7 % 4 * 2; -2 ** 2; 2 ** 3 ** 2; 1 + 2 < 1 | 4; (1 | 2) == 3;`,
+			Expected: []any{
+				// 1 | (2 ^ (3 & (5 << 2)))
+				three,
+				// 2 + ((7 % 4) * 2)
+				internal.NewLiteralInt(8),
+				internal.NewLiteralInt(-4),
+				internal.NewLiteralInt(512),
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(true),
+			},
+		},
+		{
+			Name: "not equal",
+			Code: `1 != 2; 1 != 1.0; "a" != "a"; nil != false;`,
+			Expected: []any{
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(false),
+				internal.NewLiteralBool(false),
+				internal.NewLiteralBool(true),
+			},
+		},
+	}
+
+	operatorErrors = []ErrorCase{
+		{Code: `1.5 % 2;`, Err: "1:5: operator % can be used only with integer numbers near '%'"},
+		{Code: `1 & 2.0;`, Err: "1:3: operator & can be used only with integer numbers near '&'"},
+		{Code: `1 % 0;`, Err: "1:3: division by zero near '%'"},
+		{Code: `1 << -1;`, Err: "1:3: negative shift count near '<<'"},
+		{Code: `"a" ** 2;`, Err: "1:5: type missmatch near '**'"},
+	}
+
+	constructorErrors = []ErrorCase{
+		{Code: `fun f(a) {} f();`, Err: "1:14: expected 1 arguments but got 0 near 'f'"},
+		{Code: `fun f(a) {} f(1, 2);`, Err: "1:14: expected 1 arguments but got 2 near 'f'"},
+		{Code: `class A { init(a) {} } A();`, Err: "1:25: expected 1 arguments but got 0 near 'A'"},
+		{Code: `class A {} A(1);`, Err: "1:13: expected 0 arguments but got 1 near 'A'"},
+	}
+
+	inheritanceErrors = []ErrorCase{
+		{Code: `var A = 1; class B < A {}`, Err: "1:22: superclass must be a class near 'A'"},
+	}
+
+	runtimeErrors = []ErrorCase{
+		{Code: `print 1 + x;`, Err: "1:11: undefined variable near 'x'"},
+		{Code: `var a = true; a - 1;`, Err: "1:17: type missmatch near '-'"},
+		{Code: `class A {} A().b;`, Err: "1:16: undefined field: b near 'b'"},
+		{Code: `var a = 1; a.b = 2;`, Err: "1:14: only instances have fields near 'b'"},
+		{Code: `fun f(a) {} f();`, Err: "1:14: expected 1 arguments but got 0 near 'f'"},
+		{Code: `"str"();`, Err: "1:6: this type is not callable near '('"},
+	}
+
+	listErrors = []ErrorCase{
+		{Code: `[1, 2][2];`, Err: "1:7: index out of range: index 2 with length 2 near '['"},
+		{Code: `[1, 2][-3];`, Err: "1:7: index out of range: index -3 with length 2 near '['"},
+		{Code: `var xs = []; xs[0] = 1;`, Err: "1:16: index out of range: index 0 with length 0 near '['"},
+		{Code: `[1, 2][0:3];`, Err: "1:7: index out of range: index 3 with length 2 near '['"},
+		{Code: `[1, 2][2:1];`, Err: "1:7: invalid slice indices: 2 > 1 near '['"},
+		{Code: `var a = {}; a[1:];`, Err: "1:14: only lists can be sliced near '['"},
+		{Code: `[1, 2]["a"];`, Err: "1:7: list index must be an integer near '['"},
+		{Code: `var a = 1; a[0];`, Err: "1:13: only lists and maps can be indexed near '['"},
+	}
+
+	mapErrors = []ErrorCase{
+		{Code: `var m = {"a": 1}; m["b"];`, Err: "1:20: undefined key: b near '['"},
+		{Code: `var m = {}; m[[]] = 1;`, Err: "1:14: map key must be a string, a number or a bool near '['"},
+		{Code: `var m = {nil: 1};`, Err: "1:9: map key must be a string, a number or a bool near '{'"},
+	}
+
+	integers = []Case{
+		{
+			Name: "overflow promotes to bignum",
+			Code: `9223372036854775807 + 1; -9223372036854775807 - 2; 4294967296 * 4294967296; 2 ** 100; 1 << 64;`,
+			Expected: []any{
+				bigint("9223372036854775808"),
+				bigint("-9223372036854775809"),
+				bigint("18446744073709551616"),
+				bigint("1267650600228229401496703205376"),
+				bigint("18446744073709551616"),
+			},
+		},
+		{
+			Name: "bignum shrinks back",
+			Code: `var a = 2 ** 64; a - a + 1; a / 2 ** 60; a >> 64; -(-9223372036854775807 - 1) - 1;`,
+			Expected: []any{
+				one,
+				internal.NewLiteralInt(16),
+				internal.NewLiteralInt(1),
+				internal.NewLiteralInt(9223372036854775807),
+			},
+		},
+		{
+			Name: "literal",
+			Code: `123456789012345678901234567890; -123456789012345678901234567890 % 1000;`,
+			Expected: []any{
+				bigint("123456789012345678901234567890"),
+				internal.NewLiteralInt(-890),
+			},
+		},
+		{
+			Name: "comparison with numbers",
+			Code: `2 ** 64 > 9223372036854775807; 2 ** 64 == 18446744073709551616.0; 2 ** 64 + 1 > 18446744073709551616.0; 2 ** 64 < 0.5; 2 ** 64 == "a";`,
+			Expected: []any{
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(false),
+				internal.NewLiteralBool(false),
+			},
+		},
+		{
+			Name: "mixed with floats",
+			Code: `2 ** 64 + 0.5; 2 ** 64 * 1.0;`,
+			Expected: []any{
+				internal.NewLiteralFloat(18446744073709551616.5),
+				internal.NewLiteralFloat(18446744073709551616),
+			},
+		},
+		{
+			Name: "bitwise",
+			Code: `(2 ** 64 + 5) & 7; ~(2 ** 64); (2 ** 64) | 1;`,
+			Expected: []any{
+				internal.NewLiteralInt(5),
+				bigint("-18446744073709551617"),
+				bigint("18446744073709551617"),
+			},
+		},
+		{
+			Name: "map key",
+			Code: `var m = {}; m[2 ** 64] = "a"; m[18446744073709551616.0]; m[2 ** 63 - 1] = "b"; m[9223372036854775807];`,
+			Expected: []any{
+				internal.NewLiteralString("a"),
+				internal.NewLiteralString("a"),
+				internal.NewLiteralString("b"),
+				internal.NewLiteralString("b"),
+			},
+		},
+	}
+
+	integerErrors = []ErrorCase{
+		{Code: `1 / 0;`, Err: "1:3: division by zero near '/'"},
+		{Code: `2 ** 64 / 0;`, Err: "1:9: division by zero near '/'"},
+		{Code: `2 ** 64 % 0;`, Err: "1:9: division by zero near '%'"},
+		{Code: `2 ** 10000000;`, Err: "1:3: integer is too large near '**'"},
+		{Code: `1 << 2 ** 64;`, Err: "1:3: integer is too large near '<<'"},
+	}
+
+	interpolation = []Case{
+		{
+			Name:     "expressions",
+			Code:     `var name = "Bob"; var age = 41; "hello ${name}, you are ${age + 1}";`,
+			Expected: []any{internal.NewLiteralString("hello Bob, you are 42")},
+		},
+		{
+			Name:     "values",
+			Code:     `"${nil} ${true} ${1.5} ${2 ** 64} ${[1, "a"]} ${ {"k": 2} }";`,
+			Expected: []any{internal.NewLiteralString("nil true 1.5 18446744073709551616 [1, a] {k: 2}")},
+		},
+		{
+			Name:     "functions and instances",
+			Code:     `class A { m() {} } fun f() {} var a = A(); "${A} ${a} ${f} ${a.m}";`,
+			Expected: []any{internal.NewLiteralString("<class A> <A instance> <fn f> <fn A.m>")},
+		},
+		{
+			Name:     "nested",
+			Code:     `var xs = [1, 2]; "${"${xs[0]}-${xs[1]}"}!";`,
+			Expected: []any{internal.NewLiteralString("1-2!")},
+		},
+		{
+			Name: "evaluation order",
+			Code: `
+			var log = "";
+			fun f(s) { log = log + s; return s; }
+			"${f("a")}${f("b")}";
+			log;
+			`,
+			Expected: []any{internal.NewLiteralString("ab"), internal.NewLiteralString("ab")},
+		},
+	}
+
+	interpolationErrors = []ErrorCase{
+		{Code: `"a ${1 + nil} b";`, Err: "1:8: type missmatch near '+'"},
+	}
+
+	anonymousFunctions = []Case{
+		{
+			Name:     "function expression",
+			Code:     `var add = fun (a, b) { return a + b; }; add(1, 2); fun () {}();`,
+			Expected: []any{three},
+		},
+		{
+			Name:     "arrow",
+			Code:     `var sq = (x) => x * x; sq(7); (() => 42)(); ((a, b) => a - b)(3, 1); ((x) => {"k": x})(1)["k"];`,
+			Expected: []any{internal.NewLiteralInt(49), internal.NewLiteralInt(42), two, one},
+		},
+		{
+			Name: "callback",
+			Code: `
+			fun twice(f, x) {
+				return f(f(x));
+			}
+			var k = 10;
+			twice((x) => x + k, 1);
+			twice(fun (xs) { xs[0] = xs[0] * 2; return xs; }, [3]);
+			`,
+			Expected: []any{internal.NewLiteralInt(21), list(internal.NewLiteralInt(12))},
+		},
+		{
+			Name: "closure",
+			Code: `
+			fun counter() {
+				var n = 0;
+				return () => n = n + 1;
+			}
+			var c = counter();
+			c();
+			c();
+			`,
+			Expected: []any{one, two},
+		},
+		{
+			Name: "this",
+			Code: `
+			class A {
+				init() { this.x = 1; }
+				getter() { return () => this.x; }
+			}
+			A().getter()();
+			`,
+			Expected: []any{one},
+		},
+		{
+			Name:     "grouping is not an arrow",
+			Code:     `var a = 2; (a) * 3; (1 + 2);`,
+			Expected: []any{internal.NewLiteralInt(6), three},
+		},
+	}
+
+	anonymousFunctionErrors = []ErrorCase{
+		{Code: `var f = (a) => a + nil; f(1);`, Err: "1:18: type missmatch near '+'"},
+		{Code: `(fun (a) {})();`, Err: "1:13: expected 1 arguments but got 0 near '('"},
+	}
+)
+
+var (
+	exceptions = []Case{
+		{
+			Name:     "catch thrown value",
+			Code:     `var r; try { throw "boom"; } catch (e) { r = e; } r;`,
+			Expected: []any{internal.NewLiteralString("boom")},
+		},
+		{
+			Name: "catch runtime error",
+			Code: `
+			fun f() { return nil + 1; }
+			var r;
+			try { f(); } catch (e) { r = e; }
+			r.message;
+			r.line;
+			r.stack;
+			`,
+			Expected: []any{
+				internal.NewLiteralString("type missmatch"),
+				two,
+				list(internal.NewLiteralString("f (2:25)"), internal.NewLiteralString("<script> (4:11)")),
+			},
+		},
+		{
+			Name:     "catch undefined variable",
+			Code:     `var r; try { missing; } catch (e) { r = e.message; } r;`,
+			Expected: []any{internal.NewLiteralString("undefined variable")},
+		},
+		{
+			Name:     "rethrow caught error",
+			Code:     `var r; try { try { nil + 1; } catch (e) { throw e; } } catch (e) { r = e.message; } r;`,
+			Expected: []any{internal.NewLiteralString("type missmatch")},
+		},
+		{
+			Name: "throw unwinds calls",
+			Code: `
+			fun g(x) {
+				if (x == 0) { throw "zero"; }
+				return g(x - 1);
+			}
+			var r;
+			try { g(3); } catch (e) { r = e; }
+			r;
+			`,
+			Expected: []any{internal.NewLiteralString("zero")},
+		},
+		{
+			Name:     "finally order",
+			Code:     `var log = ""; try { log = log + "t"; throw 1; } catch (e) { log = log + "c"; } finally { log = log + "f"; } log;`,
+			Expected: []any{internal.NewLiteralString("tcf")},
+		},
+		{
+			Name:     "finally without catch",
+			Code:     `var log = ""; try { try { throw "inner"; } finally { log = log + "f"; } } catch (e) { log = log + e; } log;`,
+			Expected: []any{internal.NewLiteralString("finner")},
+		},
+		{
+			Name: "return through finally",
+			Code: `
+			var log = "";
+			fun f() {
+				var x = "outer";
+				try { var x = "inner"; return x; } finally { log = log + x; }
+			}
+			f();
+			log;
+			`,
+			Expected: []any{internal.NewLiteralString("inner"), internal.NewLiteralString("outer")},
+		},
+		{
+			Name:     "finally overrides return",
+			Code:     `fun f() { try { return 1; } finally { return 2; } } f();`,
+			Expected: []any{two},
+		},
+		{
+			Name:     "finally overrides error",
+			Code:     `fun f() { try { throw 1; } finally { return 2; } } f();`,
+			Expected: []any{two},
+		},
+		{
+			Name: "break and continue through finally",
+			Code: `
+			var log = "";
+			for (var i = 0; i < 3; i = i + 1) {
+				try {
+					if (i == 0) { continue; }
+					if (i == 2) { break; }
+					log = log + "b";
+				} finally {
+					log = log + "${i}";
+				}
+			}
+			log;
+			`,
+			Expected: []any{internal.NewLiteralString("0b12")},
+		},
+		{
+			Name:     "catch variable is scoped",
+			Code:     `var e = 1; try { throw 2; } catch (e) { e = 3; } e;`,
+			Expected: []any{one},
+		},
+		{
+			Name:     "closure survives unwinding",
+			Code:     `var f; try { var x = "captured"; f = () => x; throw 1; } catch (e) {} f();`,
+			Expected: []any{internal.NewLiteralString("captured")},
+		},
+	}
+
+	exceptionErrors = []ErrorCase{
+		{Code: `throw "boom";`, Err: "1:1: uncaught exception: boom near 'throw'"},
+		{Code: `try { throw 1; } finally {}`, Err: "1:7: uncaught exception: 1 near 'throw'"},
+		{Code: `try { nil + 1; } catch (e) { throw e; }`, Err: "1:30: type missmatch near 'throw'"},
+		{Code: `try { 1; } finally { 2 + nil; }`, Err: "1:24: type missmatch near '+'"},
+	}
+)
+
+var (
+	modules = []Case{
+		{
+			Name: "import as",
+			Code: `
+			import "greet.lox" as g;
+			g.greet("world");
+			g.Greeter("class").greet();
+			g.greeting;
+			`,
+			Expected: []any{
+				internal.NewLiteralString("hello, world"),
+				internal.NewLiteralString("hello, class"),
+				internal.NewLiteralString("hello"),
+			},
+		},
+		{
+			Name: "from import",
+			Code: `
+			from "greet.lox" import greet, setGreeting;
+			setGreeting("hi");
+			greet("world");
+			`,
+			Expected: []any{internal.NewLiteralString("hi, world")},
+		},
+		{
+			Name: "module globals are not visible",
+			Code: `
+			var greeting = "main";
+			from "greet.lox" import greet;
+			greet("world");
+			greeting;
+			`,
+			Expected: []any{internal.NewLiteralString("hello, world"), internal.NewLiteralString("main")},
+		},
+		{
+			Name: "module is executed once",
+			Code: `
+			import "lib/counter.lox" as c;
+			from "lib/counter.lox" import next;
+			import "state.lox" as s;
+			c.next();
+			next();
+			s.state["counter"];
+			`,
+			Expected: []any{one, two, two},
+		},
+		{
+			Name:     "importer globals are not visible",
+			Code:     `var secret = 1; from "secret.lox" import reveal; reveal();`,
+			Expected: []any{internal.NewLiteralString("undefined variable")},
+		},
+		{
+			Name: "exports are live",
+			Code: `
+			import "tally.lox" as t;
+			t.count;
+			t.inc();
+			t.inc();
+			t.count;
+			from "tally.lox" import count;
+			count;
+			`,
+			Expected: []any{internal.NewLiteralInt(0), two, two},
+		},
+	}
+
+	moduleErrors = []ErrorCase{
+		{Code: `import "missing.lox" as m;`, Err: "1:1: cannot find module missing.lox near 'import'"},
+		{Code: `from "greet.lox" import nope;`, Err: "1:1: module greet.lox has no export nope near 'import'"},
+	}
+	stringMethods = []Case{
+		{
+			Name: "case and whitespace",
+			Code: `"Abc".upper(); "Abc".lower(); "  a b  ".trim(); "héllo".len();`,
+			Expected: []any{
+				internal.NewLiteralString("ABC"),
+				internal.NewLiteralString("abc"),
+				internal.NewLiteralString("a b"),
+				internal.NewLiteralInt(5),
+			},
+		},
+		{
+			Name: "split and join",
+			Code: `var parts = "a,b,c".split(","); parts; "-".join(parts);`,
+			Expected: []any{
+				list(internal.NewLiteralString("a"), internal.NewLiteralString("b"), internal.NewLiteralString("c")),
+				internal.NewLiteralString("a-b-c"),
+			},
+		},
+		{
+			Name: "search",
+			Code: `
+			var s = "héllo world";
+			s.contains("wor");
+			s.startsWith("hé");
+			s.endsWith("x");
+			s.indexOf("o");
+			s.indexOf("x");
+			`,
+			Expected: []any{
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(true),
+				internal.NewLiteralBool(false),
+				internal.NewLiteralInt(4),
+				internal.NewLiteralInt(-1),
+			},
+		},
+		{
+			Name: "substr counts runes",
+			Code: `var s = "привет"; s.substr(1, 3); s.substr(-2, nil); s.substr(nil, 2);`,
+			Expected: []any{
+				internal.NewLiteralString("ри"),
+				internal.NewLiteralString("ет"),
+				internal.NewLiteralString("пр"),
+			},
+		},
+		{
+			Name: "replace, repeat and padding",
+			Code: `"a-b-c".replace("-", "+"); "ab".repeat(3); "7".padLeft(3, "0"); "ab".padRight(5, "xy"); "long".padLeft(2, " "); "é".padLeft(4, "ñø");`,
+			Expected: []any{
+				internal.NewLiteralString("a+b+c"),
+				internal.NewLiteralString("ababab"),
+				internal.NewLiteralString("007"),
+				internal.NewLiteralString("abxyx"),
+				internal.NewLiteralString("long"),
+				internal.NewLiteralString("ñøñé"),
+			},
+		},
+		{
+			Name:     "chars and ord",
+			Code:     `"añ".chars(); "ñ".ord();`,
+			Expected: []any{list(internal.NewLiteralString("a"), internal.NewLiteralString("ñ")), internal.NewLiteralInt(241)},
+		},
+		{
+			Name:     "method is a value bound to its string",
+			Code:     `var up = "abc".upper; up(); "${"a".repeat(2)}!";`,
+			Expected: []any{internal.NewLiteralString("ABC"), internal.NewLiteralString("aa!")},
+		},
+	}
+
+	stringMethodErrors = []ErrorCase{
+		{Code: `"abc".nope();`, Err: "1:7: undefined string method: nope near 'nope'"},
+		{Code: `"abc".upper(1);`, Err: "1:12: expected 0 arguments but got 1 near 'upper'"},
+		{Code: `"abc".contains(1);`, Err: "1:15: argument sub of contains must be a string, got int near 'contains'"},
+		{Code: `",".join([1]);`, Err: "1:9: element 0 of join must be a string, got int near 'join'"},
+		{Code: `"abc".substr(2, 1);`, Err: "1:13: invalid substr indices: 2 > 1 near 'substr'"},
+		{Code: `"abc".substr(0, 4);`, Err: "1:13: index out of range: index 4 with length 3 near 'substr'"},
+		{Code: `"ab".repeat(-1);`, Err: "1:12: argument n of repeat must not be negative near 'repeat'"},
+		{Code: `"ab".padLeft(3, "");`, Err: "1:13: argument pad of padLeft must not be empty near 'padLeft'"},
+		{Code: `"".padLeft(2147483647, "😀");`, Err: "1:11: result of padLeft is too large near 'padLeft'"},
+		{Code: `"ab".ord();`, Err: "1:9: ord expects a single character, got 2 near 'ord'"},
+		{Code: `var n = 1; n.upper();`, Err: "1:14: only instances and strings have properties near 'upper'"},
+	}
+)
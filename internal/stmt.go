@@ -11,6 +11,12 @@ type StmtVisitor interface {
 	VisitFuncStmt(s FuncStmt) any
 	VisitReturnStmt(s RreturnStmt) any
 	VisitClassStmt(s ClassStmt) any
+	VisitWhileStmt(s WhileStmt) any
+	VisitBreakStmt(s BreakStmt) any
+	VisitContinueStmt(s ContinueStmt) any
+	VisitThrowStmt(s ThrowStmt) any
+	VisitTryStmt(s TryStmt) any
+	VisitImportStmt(s ImportStmt) any
 }
 
 type Stmt interface {
@@ -34,6 +40,8 @@ func (e PrintStmt) Accept(v StmtVisitor) any {
 }
 
 type VarStmt struct {
+	// Position is a place of the variable name.
+	Position
 	Name       string
 	Expression Expr
 }
@@ -70,6 +78,8 @@ func (e ElseStmt) Accept(v StmtVisitor) any {
 }
 
 type ForStmt struct {
+	// Position is a place of the keyword for.
+	Position
 	Initializer Stmt
 	Condition   Expr
 	Step        Expr
@@ -81,6 +91,8 @@ func (e ForStmt) Accept(v StmtVisitor) any {
 }
 
 type FuncStmt struct {
+	// Position is a place of the function name.
+	Position
 	Name       string
 	Parameters []string
 	Body       Stmt
@@ -91,6 +103,7 @@ func (e FuncStmt) Accept(v StmtVisitor) any {
 }
 
 type RreturnStmt struct {
+	Position
 	Expression Expr
 }
 
@@ -99,10 +112,75 @@ func (e RreturnStmt) Accept(v StmtVisitor) any {
 }
 
 type ClassStmt struct {
-	Name    string
-	Methods []FuncStmt
+	// Position is a place of the class name.
+	Position
+	Name string
+	// Superclass is nil when the class has no parent.
+	Superclass Expr
+	Methods    []FuncStmt
 }
 
 func (e ClassStmt) Accept(v StmtVisitor) any {
 	return v.VisitClassStmt(e)
 }
+
+type WhileStmt struct {
+	// Position is a place of the keyword while.
+	Position
+	Condition Expr
+	Body      Stmt
+}
+
+func (e WhileStmt) Accept(v StmtVisitor) any {
+	return v.VisitWhileStmt(e)
+}
+
+type BreakStmt struct {
+	Position
+}
+
+func (e BreakStmt) Accept(v StmtVisitor) any {
+	return v.VisitBreakStmt(e)
+}
+
+type ContinueStmt struct {
+	Position
+}
+
+func (e ContinueStmt) Accept(v StmtVisitor) any {
+	return v.VisitContinueStmt(e)
+}
+
+type ThrowStmt struct {
+	Position
+	Expression Expr
+}
+
+func (e ThrowStmt) Accept(v StmtVisitor) any {
+	return v.VisitThrowStmt(e)
+}
+
+// TryStmt is `try body catch (Name) Catch finally Finally`;
+// Catch is nil without the catch clause and Finally is nil without the finally clause.
+type TryStmt struct {
+	Body    Stmt
+	Name    string
+	Catch   Stmt
+	Finally Stmt
+}
+
+func (e TryStmt) Accept(v StmtVisitor) any {
+	return v.VisitTryStmt(e)
+}
+
+// ImportStmt is either `import "Path" as Name;` or `from "Path" import Names;`.
+type ImportStmt struct {
+	Position
+	Path  string
+	Name  string
+	Names []string
+}
+
+func (e ImportStmt) Accept(v StmtVisitor) any {
+	return v.VisitImportStmt(e)
+}
@@ -0,0 +1,92 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxCallDepth limits the depth of calls when it is not set explicitly,
+// so an infinite recursion fails instead of exhausting the Go stack.
+const DefaultMaxCallDepth = 4096
+
+var (
+	// ErrBudgetExceeded is returned when the script runs more steps, makes deeper calls
+	// or works longer than it is allowed.
+	ErrBudgetExceeded = errors.New("budget exceeded")
+	// ErrCanceled is returned when the context of the script is done.
+	ErrCanceled = errors.New("execution canceled")
+)
+
+// Budget limits the execution of untrusted scripts. A step is an iteration of a loop or a call.
+// Zero fields do not limit the execution.
+type Budget struct {
+	Context      context.Context
+	MaxSteps     int
+	MaxCallDepth int
+	Deadline     time.Time
+	steps        int
+}
+
+// Step counts the step and reports whether the execution may go on.
+func (b *Budget) Step() error {
+	b.steps++
+	if b.MaxSteps > 0 && b.steps > b.MaxSteps {
+		return fmt.Errorf("%w: more than %d steps", ErrBudgetExceeded, b.MaxSteps)
+	}
+	if !b.Deadline.IsZero() && time.Now().After(b.Deadline) {
+		return b.deadlineExceeded()
+	}
+	if b.Context != nil {
+		if err := b.Context.Err(); err != nil {
+			return canceled(err)
+		}
+	}
+
+	return nil
+}
+
+// Sleep waits for d; it fails like Step when the context is done or the deadline passes before.
+func (b *Budget) Sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	var deadline <-chan time.Time
+	if !b.Deadline.IsZero() {
+		t := time.NewTimer(time.Until(b.Deadline))
+		defer t.Stop()
+		deadline = t.C
+	}
+
+	var done <-chan struct{}
+	if b.Context != nil {
+		done = b.Context.Done()
+	}
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-deadline:
+		return b.deadlineExceeded()
+	case <-done:
+		return canceled(b.Context.Err())
+	}
+}
+
+// Call is like Step but also checks the depth of the call.
+func (b *Budget) Call(depth int) error {
+	if b.MaxCallDepth > 0 && depth > b.MaxCallDepth {
+		return fmt.Errorf("%w: call depth exceeds %d", ErrBudgetExceeded, b.MaxCallDepth)
+	}
+
+	return b.Step()
+}
+
+func (b *Budget) deadlineExceeded() error {
+	return fmt.Errorf("%w: deadline %s", ErrBudgetExceeded, b.Deadline.Format(time.RFC3339))
+}
+
+func canceled(err error) error {
+	return fmt.Errorf("%w: %w", ErrCanceled, err)
+}
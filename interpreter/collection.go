@@ -0,0 +1,243 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+func (i *Interpreter) VisitListExpr(e internal.ListExpr) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	elements := make([]internal.Literal, 0, len(e.Elements))
+	for _, e := range e.Elements {
+		v, err := i.eval(e)
+		if err != nil {
+			return internal.LiteralNil
+		}
+		elements = append(elements, v.(internal.Literal))
+	}
+
+	ret := internal.NewLiteralList(elements)
+	if err := i.memory.AllocValue(ret); err != nil {
+		i.err = i.runtimeError(e.Position, "[", err)
+		return internal.LiteralNil
+	}
+
+	return ret
+}
+
+func (i *Interpreter) VisitMapExpr(e internal.MapExpr) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	m := internal.NewMap()
+	for idx := range e.Keys {
+		key, err := i.eval(e.Keys[idx])
+		if err != nil {
+			return internal.LiteralNil
+		}
+
+		value, err := i.eval(e.Values[idx])
+		if err != nil {
+			return internal.LiteralNil
+		}
+
+		if err := m.Set(key.(internal.Literal), value.(internal.Literal)); err != nil {
+			i.err = i.runtimeError(e.Position, "{", err)
+			return internal.LiteralNil
+		}
+	}
+
+	ret := internal.NewLiteralMap(m)
+	if err := i.memory.AllocValue(ret); err != nil {
+		i.err = i.runtimeError(e.Position, "{", err)
+		return internal.LiteralNil
+	}
+
+	return ret
+}
+
+func (i *Interpreter) VisitIndexExpr(e internal.Index) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	values, err := i.evalAll(e.Object, e.Index)
+	if err != nil {
+		return internal.LiteralNil
+	}
+
+	ret, err := Index(values[0], values[1])
+	if err != nil {
+		i.err = i.runtimeError(e.Position, "[", err)
+		return internal.LiteralNil
+	}
+
+	return ret
+}
+
+func (i *Interpreter) VisitSliceExpr(e internal.Slice) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	values, err := i.evalAll(e.Object, e.Low, e.High)
+	if err != nil {
+		return internal.LiteralNil
+	}
+
+	ret, err := Slice(values[0], values[1], values[2])
+	if err == nil {
+		err = i.memory.AllocValue(ret)
+	}
+	if err != nil {
+		i.err = i.runtimeError(e.Position, "[", err)
+		return internal.LiteralNil
+	}
+
+	return ret
+}
+
+func (i *Interpreter) VisitIndexSetExpr(e internal.IndexSet) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	values, err := i.evalAll(e.Object, e.Index, e.Value)
+	if err != nil {
+		return internal.LiteralNil
+	}
+
+	err = i.memory.AllocKey(values[0], values[1])
+	if err == nil {
+		err = SetIndex(values[0], values[1], values[2])
+	}
+	if err != nil {
+		i.err = i.runtimeError(e.Position, "[", err)
+		return internal.LiteralNil
+	}
+
+	return values[2]
+}
+
+// evalAll evaluates expressions from left to right; a missing expression is nil.
+func (i *Interpreter) evalAll(exprs ...internal.Expr) ([]internal.Literal, error) {
+	ret := make([]internal.Literal, 0, len(exprs))
+	for _, e := range exprs {
+		if e == nil {
+			ret = append(ret, internal.LiteralNil)
+			continue
+		}
+
+		v, err := i.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v.(internal.Literal))
+	}
+
+	return ret, nil
+}
+
+// Index returns the element of the list or the value of the map by idx.
+func Index(obj, idx internal.Literal) (internal.Literal, error) {
+	switch {
+	case obj.IsMap():
+		return obj.AsMap().Get(idx)
+	case obj.IsList():
+		pos, err := listIndex(idx, len(obj.AsList().Elements))
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return obj.AsList().Elements[pos], nil
+	}
+
+	return internal.LiteralNil, errors.New("only lists and maps can be indexed")
+}
+
+// SetIndex replaces the element of the list or sets the value of the map by idx.
+func SetIndex(obj, idx, value internal.Literal) error {
+	switch {
+	case obj.IsMap():
+		return obj.AsMap().Set(idx, value)
+	case obj.IsList():
+		pos, err := listIndex(idx, len(obj.AsList().Elements))
+		if err != nil {
+			return err
+		}
+
+		obj.AsList().Elements[pos] = value
+		return nil
+	}
+
+	return errors.New("only lists and maps can be indexed")
+}
+
+// Slice returns a new list with elements of obj from low to high.
+// nil bounds are the start and the end of the list.
+func Slice(obj, low, high internal.Literal) (internal.Literal, error) {
+	if !obj.IsList() {
+		return internal.LiteralNil, errors.New("only lists can be sliced")
+	}
+
+	elements := obj.AsList().Elements
+	from, to := 0, len(elements)
+	var err error
+	if !low.IsNil() {
+		from, err = sliceBound(low, len(elements))
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+	}
+	if !high.IsNil() {
+		to, err = sliceBound(high, len(elements))
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+	}
+	if from > to {
+		return internal.LiteralNil, fmt.Errorf("invalid slice indices: %d > %d", from, to)
+	}
+
+	// the slice is a new list which does not share elements with the original one
+	ret := make([]internal.Literal, to-from)
+	copy(ret, elements[from:to])
+
+	return internal.NewLiteralList(ret), nil
+}
+
+// sliceBound is like listIndex but the bound may be equal to the length of the list.
+func sliceBound(idx internal.Literal, length int) (int, error) {
+	return normalizeIndex(idx, length, length+1)
+}
+
+// listIndex converts the index of a Lox list to the index of Go slice.
+// Negative index counts from the end of the list.
+func listIndex(idx internal.Literal, length int) (int, error) {
+	return normalizeIndex(idx, length, length)
+}
+
+// normalizeIndex checks that idx is in [-length, limit) and makes a negative idx positive.
+func normalizeIndex(idx internal.Literal, length, limit int) (int, error) {
+	if !idx.IsInt() {
+		return 0, errors.New("list index must be an integer")
+	}
+
+	ret := idx.AsInt()
+	if ret < 0 {
+		ret += int64(length)
+	}
+	if ret < 0 || ret >= int64(limit) {
+		return 0, fmt.Errorf("%w: index %d with length %d", ErrIndexOutOfRange, idx.AsInt(), length)
+	}
+
+	return int(ret), nil
+}
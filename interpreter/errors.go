@@ -0,0 +1,123 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+const (
+	// scriptFrame is a name of the frame which executes top-level statements.
+	scriptFrame = "<script>"
+	// anonymousFrame is a name of the frame of an anonymous function.
+	anonymousFrame = "<anonymous>"
+)
+
+// Frame is an active call of a function at the moment of a runtime error.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+}
+
+func (f Frame) String() string {
+	return f.Function + " (" + location(f.File, f.Line, f.Column) + ")"
+}
+
+// RuntimeError is an error which occurred during the execution of a program.
+type RuntimeError struct {
+	File   string
+	Line   int
+	Column int
+	// Near is a piece of the source code where the error occurred: an operator, a variable or a property name.
+	Near string
+	Err  error
+	// Stack is a call stack from the innermost frame to the outermost one.
+	Stack []Frame
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s: %s near '%s'", location(e.File, e.Line, e.Column), e.Err, e.Near)
+}
+
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+// Trace returns the error message followed by the call stack, one frame per line.
+func (e *RuntimeError) Trace() string {
+	lines := []string{e.Error()}
+	for _, f := range e.Stack {
+		lines = append(lines, "\tat "+f.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Report writes the uncaught error to w; runtime errors are written with the call stack.
+func Report(w io.Writer, err error) {
+	var rErr *RuntimeError
+	if errors.As(err, &rErr) {
+		fmt.Fprintln(w, rErr.Trace())
+	} else {
+		fmt.Fprintln(w, err.Error())
+	}
+}
+
+func location(file string, line, column int) string {
+	ret := fmt.Sprintf("%d:%d", line, column)
+	if file != "" {
+		ret = file + ":" + ret
+	}
+
+	return ret
+}
+
+// call is a function call which is being executed.
+type call struct {
+	function string
+	// file is a name of the module which makes the call.
+	file string
+	internal.Position
+}
+
+// stack returns frames of the active calls when the error occurred at pos.
+func (i *Interpreter) stack(pos internal.Position) []Frame {
+	var ret []Frame
+	file := i.file
+	for idx := len(i.calls); idx >= 0; idx-- {
+		function := scriptFrame
+		if idx > 0 {
+			function = i.calls[idx-1].function
+		}
+
+		ret = append(ret, Frame{Function: function, File: file, Line: pos.Line, Column: pos.Column})
+		if idx > 0 {
+			pos, file = i.calls[idx-1].Position, i.calls[idx-1].file
+		}
+	}
+
+	return ret
+}
+
+// runtimeError attaches the position and the call stack to err.
+// Runtime errors are returned as is, so the innermost position is kept.
+func (i *Interpreter) runtimeError(pos internal.Position, near string, err error) error {
+	var rErr *RuntimeError
+	if errors.As(err, &rErr) {
+		return err
+	}
+
+	return &RuntimeError{
+		File:   i.file,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Near:   near,
+		Err:    err,
+		Stack:  i.stack(pos),
+	}
+}
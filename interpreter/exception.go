@@ -0,0 +1,55 @@
+package interpreter
+
+import (
+	"errors"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+// errorClass is a class of instances which represent runtime errors inside of a script.
+var errorClass = &internal.Class{Name: "Error", Methods: map[string]internal.Literal{}}
+
+// Exception is a value which is thrown by the throw statement.
+type Exception struct {
+	Value internal.Literal
+}
+
+func (e *Exception) Error() string {
+	if e.Value.IsClassInstance() && e.Value.AsClassInstance().Class == errorClass {
+		message, _ := e.Value.AsClassInstance().Get("message")
+		return message.String()
+	}
+
+	return "uncaught exception: " + e.Value.String()
+}
+
+// Catch returns the value which is bound to the variable of a catch clause.
+// Runtime errors become Error instances with message, line and stack fields;
+// exhausted budgets, cancellation and the memory limit can not be caught.
+func Catch(err error) (internal.Literal, bool) {
+	if errors.Is(err, ErrBudgetExceeded) || errors.Is(err, ErrCanceled) || errors.Is(err, ErrMemoryLimit) {
+		return internal.LiteralNil, false
+	}
+
+	var exception *Exception
+	if errors.As(err, &exception) {
+		return exception.Value, true
+	}
+
+	var rErr *RuntimeError
+	if !errors.As(err, &rErr) {
+		return internal.LiteralNil, false
+	}
+
+	var stack []internal.Literal
+	for _, f := range rErr.Stack {
+		stack = append(stack, internal.NewLiteralString(f.String()))
+	}
+
+	ret := internal.NewLiteralClassInstance(errorClass)
+	ret.AsClassInstance().Set("message", internal.NewLiteralString(rErr.Err.Error()))
+	ret.AsClassInstance().Set("line", internal.NewLiteralInt(int64(rErr.Line)))
+	ret.AsClassInstance().Set("stack", internal.NewLiteralList(stack))
+
+	return ret, true
+}
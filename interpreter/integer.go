@@ -0,0 +1,132 @@
+package interpreter
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+// MaxIntBits limits the size of bignums, so a script can not allocate a huge integer by a single operation
+// or by a conversion like int of the standard library.
+const MaxIntBits = 1 << 20
+
+// checked applies the operation to int64 operands by small; if an operand is a bignum
+// or small reports the overflow, the operation is repeated by large with bignums.
+func checked(left, right internal.Literal, small func(a, b int64) (int64, bool), large func(z, x, y *big.Int) *big.Int) (internal.Literal, error) {
+	if left.IsInt() && right.IsInt() {
+		if ret, ok := small(left.AsInt(), right.AsInt()); ok {
+			return internal.NewLiteralInt(ret), nil
+		}
+	}
+
+	ret := large(new(big.Int), left.AsBigInt(), right.AsBigInt())
+	if ret.BitLen() > MaxIntBits {
+		return internal.LiteralNil, ErrIntegerTooLarge
+	}
+
+	return internal.NewLiteralBigInt(ret), nil
+}
+
+func isZero(l internal.Literal) bool {
+	return l.AsBigInt().Sign() == 0
+}
+
+func addInt(a, b int64) (int64, bool) {
+	ret := a + b
+	return ret, (ret > a) == (b > 0)
+}
+
+func subInt(a, b int64) (int64, bool) {
+	ret := a - b
+	return ret, (ret < a) == (b > 0)
+}
+
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	ret := a * b
+	return ret, ret/b == a
+}
+
+func divInt(a, b int64) (int64, bool) {
+	if a == math.MinInt64 && b == -1 {
+		return 0, false
+	}
+
+	return a / b, true
+}
+
+func shiftLeft(a, b int64) (int64, bool) {
+	if b >= 63 {
+		return 0, false
+	}
+
+	ret := a << b
+	return ret, ret>>b == a
+}
+
+// powInt computes a ** b for b >= 0 by squaring.
+func powInt(a, b int64) (int64, bool) {
+	ret := int64(1)
+	for ok := true; b > 0; b >>= 1 {
+		if b&1 == 1 {
+			if ret, ok = mulInt(ret, a); !ok {
+				return 0, false
+			}
+		}
+		if b > 1 {
+			if a, ok = mulInt(a, a); !ok {
+				return 0, false
+			}
+		}
+	}
+
+	return ret, true
+}
+
+// compareNumbers returns -1, 0 or 1 like big.Int.Cmp; comparable is false if an operand is NaN.
+// Bignums are compared with floats exactly.
+func compareNumbers(left, right internal.Literal) (c int, comparable bool) {
+	switch {
+	case left.IsInt() && right.IsInt():
+		a, b := left.AsInt(), right.AsInt()
+		return cmpOrdered(a, b), true
+	case left.IsInteger() && right.IsInteger():
+		return left.AsBigInt().Cmp(right.AsBigInt()), true
+	}
+
+	a, b := left.AsFloat(), right.AsFloat()
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return 0, false
+	}
+	if left.IsBigInt() || right.IsBigInt() {
+		return bigFloat(left).Cmp(bigFloat(right)), true
+	}
+
+	return cmpOrdered(a, b), true
+}
+
+func bigFloat(l internal.Literal) *big.Float {
+	if l.IsFloat() {
+		return big.NewFloat(l.AsFloat())
+	}
+
+	return new(big.Float).SetInt(l.AsBigInt())
+}
+
+func cmpOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -1,29 +1,162 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/nikgalushko/gan-ilox/env"
 	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/modules"
 	"github.com/nikgalushko/gan-ilox/token/kind"
 )
 
+// controlFlow is a signal which interrupts the execution of statements
+// until it is handled by an enclosing loop or function call.
+type controlFlow int8
+
+const (
+	flowNone controlFlow = iota
+	flowBreak
+	flowContinue
+	flowReturn
+)
+
 type Interpreter struct {
-	env   *env.Environment
-	stmts []internal.Stmt
-	err   error
+	// globals is the top-level environment of the module which is being executed.
+	globals *env.Environment
+	env     *env.Environment
+	stmts   []internal.Stmt
+	flow    controlFlow
+	err     error
+	// file is a name of the module which is being executed; it is used in runtime errors.
+	file string
+	// root and rootFile are globals and the file of the script.
+	root     *env.Environment
+	rootFile string
+	modules  *modules.Loader
+	calls    []call
+	// stdout, stderr and stdin are streams of the script: print writes to stdout,
+	// uncaught errors are reported to stderr and readLine reads stdin.
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+	budget Budget
+	memory Memory
+	// runtime is passed to native functions.
+	runtime *Runtime
+}
+
+type Option func(*Interpreter)
+
+// WithFile sets a name of the script which is reported in runtime errors.
+func WithFile(name string) Option {
+	return func(i *Interpreter) {
+		i.file = name
+	}
+}
+
+// WithModules sets the loader of imported modules; by default modules are searched near the importing file.
+func WithModules(l *modules.Loader) Option {
+	return func(i *Interpreter) {
+		i.modules = l
+	}
+}
+
+// WithStdout sets the writer of print statements; it is os.Stdout by default.
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stdout = w
+	}
+}
+
+// WithStderr sets the stream where uncaught errors are reported; it is os.Stderr by default.
+func WithStderr(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stderr = w
+	}
+}
+
+// WithStdin sets the input stream which is read by the builtin readLine; it is os.Stdin by default.
+func WithStdin(r io.Reader) Option {
+	return func(i *Interpreter) {
+		i.stdin = r
+	}
+}
+
+// WithContext stops the script with ErrCanceled when ctx is done.
+func WithContext(ctx context.Context) Option {
+	return func(i *Interpreter) {
+		i.budget.Context = ctx
+	}
+}
+
+// WithMaxSteps stops the script with ErrBudgetExceeded after n iterations of loops and calls.
+func WithMaxSteps(n int) Option {
+	return func(i *Interpreter) {
+		i.budget.MaxSteps = n
+	}
+}
+
+// WithMaxCallDepth overrides DefaultMaxCallDepth; zero does not limit the depth.
+func WithMaxCallDepth(n int) Option {
+	return func(i *Interpreter) {
+		i.budget.MaxCallDepth = n
+	}
+}
+
+// WithDeadline stops the script with ErrBudgetExceeded after the time t.
+func WithDeadline(t time.Time) Option {
+	return func(i *Interpreter) {
+		i.budget.Deadline = t
+	}
+}
+
+// WithMemoryLimit stops the script with ErrMemoryLimit when it allocates more than n bytes.
+func WithMemoryLimit(n int64) Option {
+	return func(i *Interpreter) {
+		i.memory.Limit = n
+	}
+}
+
+// DefineBuiltins defines builtins of the runtime like memoryUsage and readLine in the environment.
+// They are bound to the execution which calls them, so the environment may be shared by executions.
+func DefineBuiltins(e *env.Environment) {
+	e.Define(MemoryUsageBuiltin, NativeMemoryUsage())
+	e.Define(ReadLineBuiltin, NativeReadLine())
 }
 
-func New(env *env.Environment, stmts []internal.Stmt) *Interpreter {
-	return &Interpreter{env: env, stmts: stmts}
+// New creates an interpreter of stmts which have been processed by resolver.
+func New(globals *env.Environment, stmts []internal.Stmt, opts ...Option) *Interpreter {
+	i := &Interpreter{globals: globals, env: globals, stmts: stmts, stdout: os.Stdout, stderr: os.Stderr, stdin: os.Stdin}
+	i.budget.MaxCallDepth = DefaultMaxCallDepth
+	for _, opt := range opts {
+		opt(i)
+	}
+	i.root, i.rootFile = globals, i.file
+	i.runtime = NewRuntime(&i.budget, &i.memory, i.stdin)
+	if i.modules == nil {
+		builtins := env.New()
+		DefineBuiltins(builtins)
+		i.modules = modules.New(builtins)
+	}
+	DefineBuiltins(globals)
+
+	return i
 }
 
+// Interpret executes statements and returns values of top-level expression statements.
+// The uncaught error is reported to stderr and returned.
 func (i *Interpreter) Interpret() ([]any, error) {
 	var ret []any
 	for _, s := range i.stmts {
 		v, err := i.Exec(s)
 		if err != nil {
+			Report(i.stderr, err)
 			return nil, err
 		}
 		if v != nil && !v.(internal.Literal).IsNil() {
@@ -51,14 +184,21 @@ func (i *Interpreter) VisitSetExpr(e internal.SetExpr) any {
 	}
 
 	if !obj.(internal.Literal).IsClassInstance() {
-		i.err = errors.New("only instances have fields")
+		i.err = i.runtimeError(e.Position, e.Name, errors.New("only instances have fields"))
 		return internal.LiteralNil
 	}
 
 	value, err := i.eval(e.Value)
-	if err == nil {
-		obj.(internal.Literal).AsClassInstance().Set(e.Name, value.(internal.Literal))
+	if err != nil {
+		return internal.LiteralNil
+	}
+
+	instance := obj.(internal.Literal).AsClassInstance()
+	if err := i.memory.AllocField(instance, e.Name); err != nil {
+		i.err = i.runtimeError(e.Position, e.Name, err)
+		return internal.LiteralNil
 	}
+	instance.Set(e.Name, value.(internal.Literal))
 
 	return internal.LiteralNil
 }
@@ -68,11 +208,30 @@ func (i *Interpreter) VisitClassStmt(c internal.ClassStmt) any {
 		return internal.LiteralNil
 	}
 
+	var superclass *internal.Class
+	classEnv := i.env
+	if c.Superclass != nil {
+		v, err := i.eval(c.Superclass)
+		if err != nil {
+			return internal.LiteralNil
+		}
+		if !v.(internal.Literal).IsClass() {
+			variable := c.Superclass.(internal.Variable)
+			i.err = i.runtimeError(variable.Position, variable.Name, errors.New("superclass must be a class"))
+			return internal.LiteralNil
+		}
+
+		class := v.(internal.Literal).AsClass()
+		superclass = &class
+		classEnv = env.NewWithParent(i.env)
+		classEnv.Define("super", v.(internal.Literal))
+	}
+
 	methods := make(map[string]internal.Literal)
 	for _, s := range c.Methods {
-		methods[s.Name] = internal.NewLiteralUserFunction(s.Parameters, s.Body)
+		methods[s.Name] = internal.NewLiteralUserFunction(c.Name+"."+s.Name, s.Parameters, s.Body, classEnv)
 	}
-	i.env.Define(c.Name, internal.NewLiteralClass(c.Name, methods))
+	i.env.Define(c.Name, internal.NewLiteralClass(c.Name, superclass, methods))
 
 	return internal.LiteralNil
 }
@@ -84,11 +243,29 @@ func (i *Interpreter) VisitReturnStmt(s internal.RreturnStmt) any {
 	} else {
 		ret = internal.LiteralNil
 	}
-	ret = ret.(internal.Literal).AsReturnResult()
+	i.flow = flowReturn
 
 	return ret
 }
 
+func (i *Interpreter) VisitBreakStmt(s internal.BreakStmt) any {
+	i.flow = flowBreak
+	return internal.LiteralNil
+}
+
+func (i *Interpreter) VisitContinueStmt(s internal.ContinueStmt) any {
+	i.flow = flowContinue
+	return internal.LiteralNil
+}
+
+func (i *Interpreter) VisitWhileStmt(s internal.WhileStmt) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	return i.loop(s.Position, "while", s.Condition, s.Body, nil)
+}
+
 func (i *Interpreter) VisitForSmt(s internal.ForStmt) any {
 	if i.err != nil {
 		return internal.LiteralNil
@@ -108,12 +285,20 @@ func (i *Interpreter) VisitForSmt(s internal.ForStmt) any {
 		}
 	}
 
+	return i.loop(s.Position, "for", s.Condition, s.Body, s.Step)
+}
+
+// loop executes body while condition is true; the missing condition is always true.
+// step is evaluated after each iteration, including iterations interrupted by continue.
+// Each completed iteration is a step of the budget like a jump back of the compiled loop;
+// budget errors are reported at pos of the loop keyword.
+func (i *Interpreter) loop(pos internal.Position, keyword string, condition internal.Expr, body internal.Stmt, step internal.Expr) any {
 	evalCond := func() bool {
-		if s.Condition == nil {
+		if condition == nil {
 			return true
 		}
 
-		cond, err := i.eval(s.Condition)
+		cond, err := i.eval(condition)
 		if err != nil {
 			i.err = err
 			return false
@@ -123,28 +308,103 @@ func (i *Interpreter) VisitForSmt(s internal.ForStmt) any {
 	}
 
 	for evalCond() {
-		ret, err := i.Exec(s.Body)
+		ret, err := i.Exec(body)
 		if err != nil {
 			i.err = err
 			break
 		}
 
-		if lit, ok := ret.(internal.Literal); ok && lit.IsReturnResult() {
-			return lit
+		switch i.flow {
+		case flowBreak:
+			i.flow = flowNone
+			return internal.LiteralNil
+		case flowReturn:
+			return ret
+		case flowContinue:
+			i.flow = flowNone
 		}
 
-		if s.Step != nil {
-			_, err = i.eval(s.Step)
+		if step != nil {
+			_, err = i.eval(step)
 			if err != nil {
 				i.err = err
 				break
 			}
 		}
+
+		if err := i.budget.Step(); err != nil {
+			i.err = i.runtimeError(pos, keyword, err)
+			break
+		}
+	}
+
+	return internal.LiteralNil
+}
+
+func (i *Interpreter) VisitThrowStmt(s internal.ThrowStmt) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	v, err := i.eval(s.Expression)
+	if err != nil {
+		return internal.LiteralNil
 	}
+	i.err = i.runtimeError(s.Position, "throw", &Exception{Value: v.(internal.Literal)})
 
 	return internal.LiteralNil
 }
 
+func (i *Interpreter) VisitTryStmt(s internal.TryStmt) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	ret, err := i.Exec(s.Body)
+	if err != nil && s.Catch != nil {
+		if v, ok := Catch(err); ok {
+			// the error could interrupt a return statement, so its flow is discarded too
+			i.err, i.flow = nil, flowNone
+			ret = i.catch(s.Name, v, s.Catch)
+		}
+	}
+
+	if s.Finally == nil {
+		return ret
+	}
+	if _, ok := Catch(i.err); i.err != nil && !ok {
+		return internal.LiteralNil
+	}
+
+	// the pending error or control flow is resumed unless finally interrupts the execution by itself
+	err, flow := i.err, i.flow
+	if err != nil {
+		flow = flowNone
+	}
+	i.err, i.flow = nil, flowNone
+	finallyRet, _ := i.Exec(s.Finally)
+	if i.err != nil || i.flow != flowNone {
+		return finallyRet
+	}
+	i.err, i.flow = err, flow
+
+	return ret
+}
+
+// catch executes the block of the catch clause where the variable name holds the caught value.
+func (i *Interpreter) catch(name string, value internal.Literal, block internal.Stmt) any {
+	prevEnv := i.env
+	i.env = env.NewWithParent(prevEnv)
+	defer func() {
+		i.env = prevEnv
+	}()
+
+	i.env.Define(name, value)
+	ret, _ := i.Exec(block)
+
+	return ret
+}
+
 func (i *Interpreter) VisitIfStmt(s internal.IfStmt) any {
 	conditionResult, err := i.eval(s.Condition)
 	if err != nil {
@@ -179,8 +439,10 @@ func (i *Interpreter) VisitFuncStmt(s internal.FuncStmt) any {
 	}
 
 	i.env.Define(s.Name, internal.NewLiteralUserFunction(
+		s.Name,
 		s.Parameters,
 		s.Body,
+		i.env,
 	))
 
 	return internal.LiteralNil
@@ -218,7 +480,9 @@ func (i *Interpreter) VisitPrintStmt(s internal.PrintStmt) any {
 		return internal.LiteralNil
 	}
 
-	fmt.Println(val.(internal.Literal).String())
+	if _, err := fmt.Fprintln(i.stdout, val.(internal.Literal).String()); err != nil {
+		i.err = err
+	}
 
 	return internal.LiteralNil
 }
@@ -256,8 +520,8 @@ func (i *Interpreter) VisitBlockStmt(s internal.BlockStmt) any {
 			return internal.LiteralNil
 		}
 
-		if lit, ok := ret.(internal.Literal); ok && lit.IsReturnResult() {
-			return lit
+		if i.flow != flowNone {
+			return ret
 		}
 	}
 
@@ -281,51 +545,180 @@ func (i *Interpreter) VisitCallExpr(e internal.Call) any {
 		args = append(args, a.(internal.Literal))
 	}
 
-	var ret any
-	if callee.(internal.Literal).IsFunction() {
-		f := callee.(internal.Literal).AsFunction()
-		prevEnv := i.env
-		funEnv := env.NewWithParent(prevEnv)
-		i.env = funEnv
-		defer func() {
-			i.env = prevEnv
-		}()
+	if err := i.budget.Call(len(i.calls) + 1); err != nil {
+		i.err = i.runtimeError(e.Position, nearCall(e.Callee), err)
+		return internal.LiteralNil
+	}
+
+	i.calls = append(i.calls, call{function: calleeName(callee.(internal.Literal)), file: i.file, Position: e.Position})
+	ret, err := i.call(callee.(internal.Literal), args)
+	i.calls = i.calls[:len(i.calls)-1]
+	if err != nil {
+		i.err = i.runtimeError(e.Position, nearCall(e.Callee), err)
+		return internal.LiteralNil
+	}
 
-		for idx := range args {
-			i.env.Define(f.ArgumentsName[idx], args[idx])
+	return ret
+}
+
+func calleeName(callee internal.Literal) string {
+	switch {
+	case callee.IsFunction():
+		if callee.AsFunction().Name == "" {
+			return anonymousFrame
 		}
+		return callee.AsFunction().Name
+	case callee.IsClass():
+		return callee.AsClass().Name
+	}
 
-		ret, err = f.Call(args, i)
-	} else if callee.(internal.Literal).IsClass() {
-		// TODO: refactor it
-		c := callee.(internal.Literal).AsClass()
-		prevEnv := i.env
-		funEnv := env.NewWithParent(prevEnv)
-		i.env = funEnv
-		defer func() {
-			i.env = prevEnv
-		}()
+	return ""
+}
+
+// nearCall returns the name of the called function if it is known from the source code.
+func nearCall(callee internal.Expr) string {
+	switch e := callee.(type) {
+	case internal.Variable:
+		return e.Name
+	case internal.GetExpr:
+		return e.Name
+	case internal.Super:
+		return "super." + e.Method
+	}
+
+	return "("
+}
+
+// Call calls the function or the class with arguments outside of a script.
+func (i *Interpreter) Call(callee internal.Literal, args []internal.Literal) (internal.Literal, error) {
+	i.err, i.flow, i.calls = nil, flowNone, nil
+
+	return i.call(callee, args)
+}
+
+func (i *Interpreter) call(callee internal.Literal, args []internal.Literal) (internal.Literal, error) {
+	if callee.IsFunction() {
+		return i.callFunction(callee.AsFunction(), args)
+	}
 
-		if c.Initializer != nil {
-			initializer := c.Initializer.AsFunction()
-			for idx := range args {
-				i.env.Define(initializer.ArgumentsName[idx], args[idx])
+	if callee.IsClass() {
+		class := callee.AsClass()
+		instance := internal.NewLiteralClassInstance(&class)
+		if err := i.memory.AllocValue(instance); err != nil {
+			return internal.LiteralNil, err
+		}
+		if class.Initializer != nil {
+			_, err := i.callFunction(i.bind(*class.Initializer, instance).AsFunction(), args)
+			if err != nil {
+				return internal.LiteralNil, err
 			}
+		} else if len(args) != 0 {
+			return internal.LiteralNil, fmt.Errorf("expected 0 arguments but got %d", len(args))
+		}
+
+		return instance, nil
+	}
+
+	return internal.LiteralNil, errors.New("this type is not callable")
+}
+
+func (i *Interpreter) callFunction(f internal.Function, args []internal.Literal) (internal.Literal, error) {
+	if len(args) != len(f.ArgumentsName) {
+		return internal.LiteralNil, fmt.Errorf("expected %d arguments but got %d", len(f.ArgumentsName), len(args))
+	}
+	if f.IsNative() {
+		return i.memory.CallNative(i.runtime, f, args)
+	}
 
-			ret, err = c.Call(args, i)
-		} else {
-			ret, err = c.Call(nil, i)
+	prevEnv, prevGlobals, prevFile := i.env, i.globals, i.file
+	parentEnv := prevEnv
+	if closure, ok := f.Closure.(*env.Environment); ok {
+		parentEnv = closure
+		i.globals, i.file = i.moduleOf(closure)
+	}
+	i.env = env.NewWithParent(parentEnv)
+	defer func() {
+		i.env, i.globals, i.file = prevEnv, prevGlobals, prevFile
+	}()
+
+	for idx := range args {
+		i.env.Define(f.ArgumentsName[idx], args[idx])
+	}
+
+	ret, err := f.Call(args, i)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	i.flow = flowNone
+
+	if f.IsInitializer {
+		return f.Closure.Get("this")
+	}
+
+	if ret == nil {
+		return internal.LiteralNil, nil
+	}
+
+	return ret.(internal.Literal), nil
+}
+
+// moduleOf returns globals and the file of the module where the closure is declared.
+func (i *Interpreter) moduleOf(closure *env.Environment) (*env.Environment, string) {
+	for e := closure; e != nil; e = e.Parent() {
+		if file, ok := i.modules.File(e); ok {
+			return e, file
 		}
-	} else {
-		err = errors.New("this type is not callable")
 	}
 
+	return i.root, i.rootFile
+}
+
+func (i *Interpreter) VisitImportStmt(s internal.ImportStmt) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	m, err := i.modules.Import(i.file, s.Path, func(file string, stmts []internal.Stmt, globals *env.Environment) error {
+		return i.module(s.Position, file, stmts, globals)
+	})
 	if err != nil {
-		i.err = err
-		ret = internal.LiteralNil
+		i.err = i.runtimeError(s.Position, "import", err)
+		return internal.LiteralNil
 	}
 
-	return ret
+	if s.Name != "" {
+		i.env.Define(s.Name, m)
+	}
+	for _, name := range s.Names {
+		v, err := modules.Export(m, name)
+		if err != nil {
+			i.err = i.runtimeError(s.Position, "import", err)
+			return internal.LiteralNil
+		}
+		i.env.Define(name, v)
+	}
+
+	return internal.LiteralNil
+}
+
+// module executes top-level statements of the module which is imported at pos.
+func (i *Interpreter) module(pos internal.Position, file string, stmts []internal.Stmt, globals *env.Environment) error {
+	prevEnv, prevGlobals, prevFile := i.env, i.globals, i.file
+	i.calls = append(i.calls, call{function: scriptFrame, file: i.file, Position: pos})
+	i.env, i.globals, i.file = globals, globals, file
+	defer func() {
+		i.env, i.globals, i.file = prevEnv, prevGlobals, prevFile
+		i.calls = i.calls[:len(i.calls)-1]
+	}()
+
+	for _, s := range stmts {
+		if _, err := i.Exec(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (i *Interpreter) VisitLogicalExpr(e internal.Logical) any {
@@ -368,7 +761,15 @@ func (i *Interpreter) VisitAssignmentExpr(e internal.Assignment) any {
 		return internal.LiteralNil
 	}
 
-	i.env.Assign(e.Name, val.(internal.Literal))
+	if e.Depth == internal.GlobalDepth {
+		err = i.globals.Assign(e.Name, val.(internal.Literal))
+	} else {
+		err = i.env.AssignAt(e.Depth, e.Name, val.(internal.Literal))
+	}
+	if err != nil {
+		i.err = i.runtimeError(e.Position, e.Name, err)
+		return internal.LiteralNil
+	}
 
 	return val
 }
@@ -378,9 +779,17 @@ func (i *Interpreter) VisitVariableExpr(e internal.Variable) any {
 		return internal.LiteralNil
 	}
 
-	val, err := i.env.Get(e.Name)
+	var (
+		val internal.Literal
+		err error
+	)
+	if e.Depth == internal.GlobalDepth {
+		val, err = i.globals.Get(e.Name)
+	} else {
+		val, err = i.env.GetAt(e.Depth, e.Name)
+	}
 	if err != nil {
-		i.err = err
+		i.err = i.runtimeError(e.Position, e.Name, err)
 		return internal.LiteralNil
 	}
 
@@ -392,29 +801,22 @@ func (i *Interpreter) VisitBinaryExpr(expression internal.Binary) any {
 		return internal.LiteralNil
 	}
 
-	left := expression.Left.Accept(i).(internal.Literal)
-	right := expression.Right.Accept(i).(internal.Literal)
-
-	var ret internal.Literal
-	switch expression.Operator {
-	case kind.Minus:
-		ret, i.err = sub(left, right)
-	case kind.Plus:
-		ret, i.err = add(left, right)
-	case kind.Slash:
-		ret, i.err = div(left, right)
-	case kind.Star:
-		ret, i.err = mul(left, right)
-	case kind.Less:
-		ret, i.err = less(left, right)
-	case kind.LessEqual:
-		ret, i.err = lessOrEqual(left, right)
-	case kind.Greater:
-		ret, i.err = graeater(left, right)
-	case kind.GreaterEqual:
-		ret, i.err = graeaterOrEqual(left, right)
-	case kind.EqualEqual:
-		ret, i.err = equal(left, right)
+	l, err := i.eval(expression.Left)
+	if err != nil {
+		return internal.LiteralNil
+	}
+	r, err := i.eval(expression.Right)
+	if err != nil {
+		return internal.LiteralNil
+	}
+
+	ret, err := Binary(expression.Operator, l.(internal.Literal), r.(internal.Literal))
+	if err == nil {
+		err = i.memory.AllocValue(ret)
+	}
+	if err != nil {
+		i.err = i.runtimeError(expression.Position, expression.Operator.String(), err)
+		return internal.LiteralNil
 	}
 
 	return ret
@@ -434,34 +836,54 @@ func (i *Interpreter) VisitLiteralExpr(expression internal.LiteralExpr) any {
 	return expression.Value
 }
 
-func (i *Interpreter) VisitUnaryExpr(expression internal.Unary) any {
+func (i *Interpreter) VisitInterpolationExpr(e internal.Interpolation) any {
 	if i.err != nil {
 		return internal.LiteralNil
 	}
 
-	val := expression.Right.Accept(i).(internal.Literal)
-
-	switch expression.Operator {
-	case kind.Bang:
-		return internal.NewLiteralBool(!val.AsBool())
-	case kind.Minus:
-		if val.IsInt() {
-			return internal.NewLiteralInt(-val.AsInt())
-		} else if val.IsFloat() {
-			return internal.NewLiteralFloat(-val.AsFloat())
+	var b strings.Builder
+	for _, part := range e.Parts {
+		v, err := i.eval(part)
+		if err != nil {
+			return internal.LiteralNil
 		}
+		b.WriteString(v.(internal.Literal).String())
+	}
 
-		i.err = errors.New("Illegal operation") // TODO: craete more freandly error message
+	ret := internal.NewLiteralString(b.String())
+	if err := i.memory.AllocValue(ret); err != nil {
+		i.err = i.runtimeError(e.Position, "\"", err)
 		return internal.LiteralNil
-	case kind.BitwiseNot:
-		if val.IsInt() {
-			return internal.NewLiteralInt(^val.AsInt())
-		}
-		i.err = errors.New("bitwise operator can be used only with integer number")
+	}
+
+	return ret
+}
+
+func (i *Interpreter) VisitFunctionExpr(e internal.FunctionExpr) any {
+	if i.err != nil {
 		return internal.LiteralNil
 	}
 
-	panic("unreachable code")
+	return internal.NewLiteralUserFunction("", e.Parameters, e.Body, i.env)
+}
+
+func (i *Interpreter) VisitUnaryExpr(expression internal.Unary) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	v, err := i.eval(expression.Right)
+	if err != nil {
+		return internal.LiteralNil
+	}
+
+	ret, err := Unary(expression.Operator, v.(internal.Literal))
+	if err != nil {
+		i.err = i.runtimeError(expression.Position, expression.Operator.String(), err)
+		return internal.LiteralNil
+	}
+
+	return ret
 }
 
 func (i *Interpreter) VisitGetExpr(e internal.GetExpr) any {
@@ -474,16 +896,80 @@ func (i *Interpreter) VisitGetExpr(e internal.GetExpr) any {
 		return internal.LiteralNil
 	}
 
+	if v.(internal.Literal).IsString() {
+		ret, err := StringMethod(v.(internal.Literal), e.Name)
+		if err != nil {
+			i.err = i.runtimeError(e.Position, e.Name, err)
+			return internal.LiteralNil
+		}
+
+		return ret
+	}
 	if !v.(internal.Literal).IsClassInstance() {
-		i.err = fmt.Errorf("only instances have property: %w", err)
+		i.err = i.runtimeError(e.Position, e.Name, errors.New("only instances and strings have properties"))
 		return internal.LiteralNil
 	}
 
-	ret, err := v.(internal.Literal).AsClassInstance().Get(e.Name)
+	instance := v.(internal.Literal).AsClassInstance()
+	ret, err := instance.Get(e.Name)
+	if err == nil {
+		return ret
+	}
+
+	method, ok := instance.Class.FindMethod(e.Name)
+	if !ok {
+		i.err = i.runtimeError(e.Position, e.Name, err)
+		return internal.LiteralNil
+	}
+
+	return i.bind(method, v.(internal.Literal))
+}
+
+func (i *Interpreter) VisitThisExpr(e internal.This) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	val, err := i.env.GetAt(e.Depth, "this")
 	if err != nil {
-		i.err = err
+		i.err = i.runtimeError(e.Position, "this", err)
 		return internal.LiteralNil
 	}
 
-	return ret
+	return val
+}
+
+func (i *Interpreter) VisitSuperExpr(e internal.Super) any {
+	if i.err != nil {
+		return internal.LiteralNil
+	}
+
+	superclass, err := i.env.GetAt(e.Depth, "super")
+	if err != nil {
+		i.err = i.runtimeError(e.Position, "super."+e.Method, err)
+		return internal.LiteralNil
+	}
+
+	// "this" is always declared in the scope right inside the scope of "super"
+	instance, err := i.env.GetAt(e.Depth-1, "this")
+	if err != nil {
+		i.err = i.runtimeError(e.Position, "super."+e.Method, err)
+		return internal.LiteralNil
+	}
+
+	method, ok := superclass.AsClass().FindMethod(e.Method)
+	if !ok {
+		i.err = i.runtimeError(e.Position, "super."+e.Method, errors.New("undefined property: "+e.Method))
+		return internal.LiteralNil
+	}
+
+	return i.bind(method, instance)
+}
+
+// bind returns the method which sees the instance as "this".
+func (i *Interpreter) bind(method internal.Literal, instance internal.Literal) internal.Literal {
+	closure := env.NewWithParent(method.AsFunction().Closure.(*env.Environment))
+	closure.Define("this", instance)
+
+	return method.WithClosure(closure)
 }
@@ -0,0 +1,194 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/internal/programtest"
+	"github.com/nikgalushko/gan-ilox/modules"
+	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/resolver"
+	"github.com/nikgalushko/gan-ilox/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, code string) ([]any, error) {
+	t.Helper()
+
+	builtins := env.New()
+	return New(env.NewWithParent(builtins), parse(t, code), WithModules(modules.New(builtins, programtest.ModulePath)), WithStderr(io.Discard)).Interpret()
+}
+
+func parse(t *testing.T, code string) []internal.Stmt {
+	t.Helper()
+
+	tokens, err := scanner.NewScanner(code).ScanTokens()
+	require.NoError(t, err)
+
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+
+	stmts, err = resolver.New().Resolve(stmts)
+	require.NoError(t, err)
+
+	return stmts
+}
+
+func TestInterpreter_Programs(t *testing.T) {
+	programtest.Run(t, run)
+}
+
+func TestInterpreter_Golden(t *testing.T) {
+	programtest.Golden(t, "testdata", func(t *testing.T, code string, out io.Writer) {
+		_, _ = New(env.New(), parse(t, code), WithStdout(out), WithStderr(out)).Interpret()
+	})
+}
+
+func TestInterpreter_SharedModules(t *testing.T) {
+	builtins := env.New()
+	DefineBuiltins(builtins)
+	loader := modules.New(builtins, programtest.ModulePath)
+	code := `from "input.lox" import read; read();`
+
+	first := New(env.NewWithParent(builtins), parse(t, code), WithModules(loader), WithStdin(strings.NewReader("first")))
+	second := New(env.NewWithParent(builtins), parse(t, code), WithModules(loader), WithStdin(strings.NewReader("second")))
+
+	ret, err := first.Interpret()
+	require.NoError(t, err)
+	require.Equal(t, []any{internal.NewLiteralString("first")}, ret)
+
+	ret, err = second.Interpret()
+	require.NoError(t, err)
+	require.Equal(t, []any{internal.NewLiteralString("second")}, ret)
+}
+
+func TestInterpreter_RuntimeError(t *testing.T) {
+	_, err := run(t, `var a = true; a - 1;`)
+
+	var rErr *RuntimeError
+	require.ErrorAs(t, err, &rErr)
+	require.EqualError(t, rErr.Err, "type missmatch")
+	require.Equal(t, 1, rErr.Line)
+	require.Equal(t, 17, rErr.Column)
+	require.Equal(t, "-", rErr.Near)
+}
+
+func TestInterpreter_RuntimeErrorStack(t *testing.T) {
+	stmts := parse(t, `class Box {
+	open() {
+		return this.lid;
+	}
+}
+fun unpack(b) {
+	return b.open();
+}
+unpack(Box());
+`)
+
+	var stderr strings.Builder
+	_, err := New(env.New(), stmts, WithFile("box.lox"), WithStderr(&stderr)).Interpret()
+
+	var rErr *RuntimeError
+	require.ErrorAs(t, err, &rErr)
+	require.ErrorIs(t, rErr, rErr.Err)
+	require.Equal(t, []Frame{
+		{Function: "Box.open", File: "box.lox", Line: 3, Column: 15},
+		{Function: "unpack", File: "box.lox", Line: 7, Column: 15},
+		{Function: "<script>", File: "box.lox", Line: 9, Column: 7},
+	}, rErr.Stack)
+	require.Equal(t, `box.lox:3:15: undefined field: lid near 'lid'
+	at Box.open (box.lox:3:15)
+	at unpack (box.lox:7:15)
+	at <script> (box.lox:9:7)`, rErr.Trace())
+	require.Equal(t, rErr.Trace()+"\n", stderr.String())
+
+	_, err = run(t, `var f = () => nil + 1; f();`)
+	require.ErrorAs(t, err, &rErr)
+	require.Equal(t, `1:19: type missmatch near '+'
+	at <anonymous> (1:19)
+	at <script> (1:25)`, rErr.Trace())
+}
+
+func TestInterpreter_Exceptions(t *testing.T) {
+	globals := env.New()
+	globals.Define("fail", internal.NewLiteralNativeFunction("fail", nil, func(...internal.Literal) (internal.Literal, error) {
+		return internal.LiteralNil, errors.New("native failure")
+	}))
+
+	ret, err := New(globals, parse(t, `var r; try { fail(); } catch (e) { r = e.message; } r;`), WithStderr(io.Discard)).Interpret()
+	require.NoError(t, err)
+	require.Equal(t, []any{internal.NewLiteralString("native failure")}, ret)
+
+	_, err = New(env.New(), parse(t, `fun f() { throw "boom"; } f();`), WithStderr(io.Discard)).Interpret()
+	var rErr *RuntimeError
+	require.ErrorAs(t, err, &rErr)
+	require.Equal(t, `1:11: uncaught exception: boom near 'throw'
+	at f (1:11)
+	at <script> (1:28)`, rErr.Trace())
+}
+
+func TestInterpreter_IndexOutOfRange(t *testing.T) {
+	_, err := run(t, `[1, 2][2];`)
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestInterpreter_Budget(t *testing.T) {
+	programtest.RunBudgets(t, func(t *testing.T, code string, b programtest.Budget) ([]any, error) {
+		opts := []Option{WithStderr(io.Discard)}
+		if b.MaxSteps != 0 {
+			opts = append(opts, WithMaxSteps(b.MaxSteps))
+		}
+		if b.MaxCallDepth != 0 {
+			opts = append(opts, WithMaxCallDepth(b.MaxCallDepth))
+		}
+
+		return New(env.New(), parse(t, code), opts...).Interpret()
+	})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		code string
+		opt  Option
+		err  error
+		msg  string
+	}{
+		{
+			name: "steps",
+			code: `for {}`,
+			opt:  WithMaxSteps(100),
+			err:  ErrBudgetExceeded,
+		},
+		{
+			name: "deadline",
+			code: `while (true) {}`,
+			opt:  WithDeadline(time.Now().Add(10 * time.Millisecond)),
+			err:  ErrBudgetExceeded,
+		},
+		{
+			name: "context",
+			code: `for {}`,
+			opt:  WithContext(canceled),
+			err:  ErrCanceled,
+			msg:  "1:1: execution canceled: context canceled near 'for'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(env.New(), parse(t, tt.code), tt.opt, WithStderr(io.Discard)).Interpret()
+			require.ErrorIs(t, err, tt.err)
+			if tt.msg != "" {
+				require.EqualError(t, err, tt.msg)
+			}
+		})
+	}
+}
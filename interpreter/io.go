@@ -0,0 +1,43 @@
+package interpreter
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+// ReadLineBuiltin is the name of the native function which reads a line of the input of the script.
+const ReadLineBuiltin = "readLine"
+
+// NativeReadLine returns the builtin which reads a line from the input of the execution which calls it
+// without the line terminator; it returns nil at the end of the input. The input is read byte by byte,
+// so nothing is buffered beyond the line and readers may be shared between scripts.
+func NativeReadLine() internal.Literal {
+	return internal.NewLiteralRuntimeFunction(ReadLineBuiltin, nil, func(rt internal.Runtime, args ...internal.Literal) (internal.Literal, error) {
+		r := rt.Stdin()
+		var (
+			line strings.Builder
+			b    [1]byte
+		)
+		for {
+			_, err := io.ReadFull(r, b[:])
+			if errors.Is(err, io.EOF) {
+				if line.Len() == 0 {
+					return internal.LiteralNil, nil
+				}
+				break
+			}
+			if err != nil {
+				return internal.LiteralNil, err
+			}
+			if b[0] == '\n' {
+				break
+			}
+			line.WriteByte(b[0])
+		}
+
+		return internal.NewLiteralString(strings.TrimSuffix(line.String(), "\r")), nil
+	})
+}
@@ -0,0 +1,139 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+// MemoryUsageBuiltin is the name of the native function which returns Memory.Used to scripts.
+const MemoryUsageBuiltin = "memoryUsage"
+
+// ErrMemoryLimit is returned when the script allocates more memory than it is allowed.
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// Approximate sizes of allocations in bytes.
+const (
+	literalSize  = int64(unsafe.Sizeof(internal.Literal{}))
+	listSize     = int64(unsafe.Sizeof(internal.List{}))
+	mapSize      = int64(unsafe.Sizeof(internal.Map{}))
+	mapEntrySize = 2*literalSize + 8
+	instanceSize = int64(unsafe.Sizeof(internal.ClassInstance{}))
+	fieldSize    = literalSize + 16
+)
+
+// Memory approximates bytes allocated by the script for strings, instances and collections.
+// Memory is never released, so Used is the total of all allocations rather than the size of live values.
+type Memory struct {
+	// Limit is the maximum of Used; zero does not limit allocations.
+	Limit int64
+	used  int64
+}
+
+func (m *Memory) Used() int64 {
+	return m.used
+}
+
+// Alloc counts n bytes and fails when the limit is exceeded.
+func (m *Memory) Alloc(n int64) error {
+	m.used += n
+	return m.Check(0)
+}
+
+// Check fails like Alloc when n more bytes exceed the limit but does not count them,
+// so values are rejected before they are built; a nil Memory does not limit allocations.
+func (m *Memory) Check(n int64) error {
+	if m != nil && m.Limit > 0 && m.used+n > m.Limit {
+		return fmt.Errorf("%w: %d bytes allocated, limit is %d", ErrMemoryLimit, m.used+n, m.Limit)
+	}
+
+	return nil
+}
+
+// AllocValue counts the new value; elements of collections are counted when they are created.
+func (m *Memory) AllocValue(v internal.Literal) error {
+	switch {
+	case v.IsString():
+		return m.Alloc(int64(len(v.AsString())))
+	case v.IsBigInt():
+		return m.Alloc(int64(len(v.AsBigInt().Bits())) * 8)
+	case v.IsList():
+		return m.Alloc(listSize + int64(len(v.AsList().Elements))*literalSize)
+	case v.IsMap():
+		return m.Alloc(mapSize + int64(v.AsMap().Len())*mapEntrySize)
+	case v.IsClassInstance():
+		return m.Alloc(instanceSize + int64(len(v.AsClassInstance().Fields))*fieldSize)
+	}
+
+	return nil
+}
+
+// AllocField counts the field which is set to the instance unless the instance already has it.
+func (m *Memory) AllocField(instance internal.ClassInstance, name string) error {
+	if _, ok := instance.Fields[name]; ok {
+		return nil
+	}
+
+	return m.Alloc(fieldSize + int64(len(name)))
+}
+
+// AllocKey counts the entry which is added to the map unless the map already has the key.
+func (m *Memory) AllocKey(obj, key internal.Literal) error {
+	if !obj.IsMap() {
+		return nil
+	}
+
+	if ok, err := obj.AsMap().Has(key); err != nil || ok {
+		return nil
+	}
+
+	return m.Alloc(mapEntrySize)
+}
+
+// CallNative calls the native function by the runtime rt and counts the result and the growth
+// of collections which are passed as arguments, like the list passed to push.
+func (m *Memory) CallNative(rt internal.Runtime, f internal.Function, args []internal.Literal) (internal.Literal, error) {
+	lengths := make([]int, len(args))
+	for idx, a := range args {
+		lengths[idx] = collectionLen(a)
+	}
+
+	ret, err := f.CallNative(rt, args)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	for idx, a := range args {
+		if grown := collectionLen(a) - lengths[idx]; grown > 0 {
+			size := literalSize
+			if a.IsMap() {
+				size = mapEntrySize
+			}
+			if err := m.Alloc(int64(grown) * size); err != nil {
+				return internal.LiteralNil, err
+			}
+		}
+	}
+
+	return ret, m.AllocValue(ret)
+}
+
+// NativeMemoryUsage returns the builtin which reports Used of the memory of the execution which calls it.
+func NativeMemoryUsage() internal.Literal {
+	return internal.NewLiteralRuntimeFunction(MemoryUsageBuiltin, nil, func(rt internal.Runtime, args ...internal.Literal) (internal.Literal, error) {
+		return internal.NewLiteralInt(rt.MemoryUsed()), nil
+	})
+}
+
+func collectionLen(v internal.Literal) int {
+	switch {
+	case v.IsList():
+		return len(v.AsList().Elements)
+	case v.IsMap():
+		return v.AsMap().Len()
+	}
+
+	return 0
+}
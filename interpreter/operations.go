@@ -2,11 +2,173 @@ package interpreter
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
 
 	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/token/kind"
 )
 
-var ErrTypeMissmatch = errors.New("type missmatch")
+var (
+	ErrTypeMissmatch  = errors.New("type missmatch")
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrIntegerTooLarge is returned when the result of an integer operation needs more than MaxIntBits bits.
+	ErrIntegerTooLarge = errors.New("integer is too large")
+)
+
+// Binary applies the binary operator to the operands.
+// It is shared by all backends, so they have the same semantics of operators.
+func Binary(operator kind.TokenType, left, right internal.Literal) (internal.Literal, error) {
+	var (
+		ret internal.Literal
+		err error
+	)
+	switch operator {
+	case kind.Minus:
+		ret, err = sub(left, right)
+	case kind.Plus:
+		ret, err = add(left, right)
+	case kind.Slash:
+		ret, err = div(left, right)
+	case kind.Star:
+		ret, err = mul(left, right)
+	case kind.Less:
+		ret, err = less(left, right)
+	case kind.LessEqual:
+		ret, err = lessOrEqual(left, right)
+	case kind.Greater:
+		ret, err = graeater(left, right)
+	case kind.GreaterEqual:
+		ret, err = graeaterOrEqual(left, right)
+	case kind.EqualEqual:
+		ret, err = equal(left, right)
+	case kind.BangEqual:
+		ret, err = equal(left, right)
+		ret = internal.NewLiteralBool(!ret.AsBool())
+	case kind.Percent, kind.BitwiseAnd, kind.BitwiseOr, kind.BitwiseXor, kind.LessLess, kind.GreaterGreater:
+		ret, err = integer(operator, left, right)
+	case kind.StarStar:
+		ret, err = pow(left, right)
+	default:
+		err = fmt.Errorf("unknown binary operator %s", operator)
+	}
+
+	return ret, err
+}
+
+// Unary applies the unary operator to the operand.
+func Unary(operator kind.TokenType, val internal.Literal) (internal.Literal, error) {
+	switch operator {
+	case kind.Bang:
+		return internal.NewLiteralBool(!val.AsBool()), nil
+	case kind.Minus:
+		if val.IsInt() && val.AsInt() != math.MinInt64 {
+			return internal.NewLiteralInt(-val.AsInt()), nil
+		} else if val.IsInteger() {
+			return internal.NewLiteralBigInt(new(big.Int).Neg(val.AsBigInt())), nil
+		} else if val.IsFloat() {
+			return internal.NewLiteralFloat(-val.AsFloat()), nil
+		}
+
+		return internal.LiteralNil, errors.New("Illegal operation") // TODO: craete more freandly error message
+	case kind.BitwiseNot:
+		if val.IsInt() {
+			return internal.NewLiteralInt(^val.AsInt()), nil
+		} else if val.IsBigInt() {
+			return internal.NewLiteralBigInt(new(big.Int).Not(val.AsBigInt())), nil
+		}
+		return internal.LiteralNil, errors.New("bitwise operator can be used only with integer number")
+	}
+
+	return internal.LiteralNil, fmt.Errorf("unknown unary operator %s", operator)
+}
+
+// integer applies the operator which is defined only for integers.
+func integer(operator kind.TokenType, left, right internal.Literal) (internal.Literal, error) {
+	if !left.IsInteger() || !right.IsInteger() {
+		return internal.LiteralNil, fmt.Errorf("operator %s can be used only with integer numbers", operator)
+	}
+
+	switch operator {
+	case kind.Percent:
+		if isZero(right) {
+			return internal.LiteralNil, ErrDivisionByZero
+		}
+		return checked(left, right, func(a, b int64) (int64, bool) { return a % b, true }, (*big.Int).Rem)
+	case kind.BitwiseAnd:
+		return checked(left, right, func(a, b int64) (int64, bool) { return a & b, true }, (*big.Int).And)
+	case kind.BitwiseOr:
+		return checked(left, right, func(a, b int64) (int64, bool) { return a | b, true }, (*big.Int).Or)
+	case kind.BitwiseXor:
+		return checked(left, right, func(a, b int64) (int64, bool) { return a ^ b, true }, (*big.Int).Xor)
+	}
+
+	return shift(operator, left, right)
+}
+
+func shift(operator kind.TokenType, left, right internal.Literal) (internal.Literal, error) {
+	if right.AsBigInt().Sign() < 0 {
+		return internal.LiteralNil, errors.New("negative shift count")
+	}
+
+	if operator == kind.GreaterGreater {
+		if !right.IsInt() || right.AsInt() >= 64 && left.IsInt() {
+			// all bits are shifted out
+			if left.AsBigInt().Sign() < 0 {
+				return internal.NewLiteralInt(-1), nil
+			}
+			return internal.NewLiteralInt(0), nil
+		}
+		if left.IsInt() {
+			return internal.NewLiteralInt(left.AsInt() >> right.AsInt()), nil
+		}
+		return internal.NewLiteralBigInt(new(big.Int).Rsh(left.AsBigInt(), uint(right.AsInt()))), nil
+	}
+
+	if isZero(left) {
+		return internal.NewLiteralInt(0), nil
+	}
+	if !right.IsInt() || int64(left.AsBigInt().BitLen())+right.AsInt() > MaxIntBits {
+		return internal.LiteralNil, ErrIntegerTooLarge
+	}
+
+	return checked(left, right, shiftLeft, func(z, x, y *big.Int) *big.Int {
+		return z.Lsh(x, uint(y.Int64()))
+	})
+}
+
+// pow raises integers to a non-negative integer power exactly; other operands give a float.
+func pow(base, exp internal.Literal) (internal.Literal, error) {
+	if !base.IsNumber() || !exp.IsNumber() {
+		return internal.LiteralNil, ErrTypeMissmatch
+	}
+
+	if !base.IsInteger() || !exp.IsInteger() || exp.AsBigInt().Sign() < 0 {
+		return internal.NewLiteralFloat(math.Pow(base.AsFloat(), exp.AsFloat())), nil
+	}
+
+	b := base.AsBigInt()
+	if b.CmpAbs(big.NewInt(1)) <= 0 {
+		// 0, 1 and -1 do not grow, so the exponent may be any integer
+		if b.Sign() < 0 && exp.AsBigInt().Bit(0) == 1 {
+			return internal.NewLiteralInt(-1), nil
+		}
+		if b.Sign() == 0 && exp.AsBigInt().Sign() > 0 {
+			return internal.NewLiteralInt(0), nil
+		}
+		return internal.NewLiteralInt(1), nil
+	}
+	// the result has at least exp bits as |base| >= 2
+	if !exp.IsInt() || exp.AsInt() > MaxIntBits || int64(b.BitLen()-1)*exp.AsInt() > MaxIntBits {
+		return internal.LiteralNil, ErrIntegerTooLarge
+	}
+
+	return checked(base, exp, powInt, func(z, x, y *big.Int) *big.Int {
+		return z.Exp(x, y, nil)
+	})
+}
 
 func add(left internal.Literal, right internal.Literal) (internal.Literal, error) {
 	if !((left.IsNumber() && right.IsNumber()) || (left.IsString() && right.IsString())) {
@@ -14,8 +176,8 @@ func add(left internal.Literal, right internal.Literal) (internal.Literal, error
 	}
 
 	if left.IsNumber() {
-		if left.IsInt() && right.IsInt() {
-			return internal.NewLiteralInt(left.AsInt() + right.AsInt()), nil
+		if left.IsInteger() && right.IsInteger() {
+			return checked(left, right, addInt, (*big.Int).Add)
 		}
 		return internal.NewLiteralFloat(left.AsFloat() + right.AsFloat()), nil
 	}
@@ -28,19 +190,23 @@ func mul(left internal.Literal, right internal.Literal) (internal.Literal, error
 		return internal.LiteralNil, ErrTypeMissmatch
 	}
 
-	if left.IsInt() && right.IsInt() {
-		return internal.NewLiteralInt(left.AsInt() * right.AsInt()), nil
+	if left.IsInteger() && right.IsInteger() {
+		return checked(left, right, mulInt, (*big.Int).Mul)
 	}
 	return internal.NewLiteralFloat(left.AsFloat() * right.AsFloat()), nil
 }
 
+// div truncates the quotient of integers; division of floats by zero gives an infinity or NaN.
 func div(left internal.Literal, right internal.Literal) (internal.Literal, error) {
 	if !left.IsNumber() || !right.IsNumber() {
 		return internal.LiteralNil, ErrTypeMissmatch
 	}
 
-	if left.IsInt() && right.IsInt() {
-		return internal.NewLiteralInt(left.AsInt() / right.AsInt()), nil
+	if left.IsInteger() && right.IsInteger() {
+		if isZero(right) {
+			return internal.LiteralNil, ErrDivisionByZero
+		}
+		return checked(left, right, divInt, (*big.Int).Quo)
 	}
 	return internal.NewLiteralFloat(left.AsFloat() / right.AsFloat()), nil
 }
@@ -50,101 +216,56 @@ func sub(a, b internal.Literal) (internal.Literal, error) {
 		return internal.LiteralNil, ErrTypeMissmatch
 	}
 
-	if a.IsInt() && b.IsInt() {
-		return internal.NewLiteralInt(a.AsInt() - b.AsInt()), nil
+	if a.IsInteger() && b.IsInteger() {
+		return checked(a, b, subInt, (*big.Int).Sub)
 	}
 	return internal.NewLiteralFloat(a.AsFloat() - b.AsFloat()), nil
 }
 
 func less(left, right internal.Literal) (internal.Literal, error) {
-	if !((left.IsNumber() && right.IsNumber()) || (left.IsString() && right.IsString())) {
-		return internal.LiteralNil, ErrTypeMissmatch
-	}
-
-	var ret bool
-	if left.IsNumber() {
-		if left.IsInt() && right.IsInt() {
-			ret = left.AsInt() < right.AsInt()
-		} else {
-			ret = left.AsFloat() < right.AsFloat()
-		}
-	} else {
-		ret = left.AsString() < right.AsString()
-	}
-
-	return internal.NewLiteralBool(ret), nil
+	return compare(left, right, func(c int) bool { return c < 0 })
 }
 
 func lessOrEqual(left, right internal.Literal) (internal.Literal, error) {
-	if !((left.IsNumber() && right.IsNumber()) || (left.IsString() && right.IsString())) {
-		return internal.LiteralNil, ErrTypeMissmatch
-	}
-
-	var ret bool
-	if left.IsNumber() {
-		if left.IsInt() && right.IsInt() {
-			ret = left.AsInt() <= right.AsInt()
-		} else {
-			ret = left.AsFloat() <= right.AsFloat()
-		}
-	} else {
-		ret = left.AsString() <= right.AsString()
-	}
-
-	return internal.NewLiteralBool(ret), nil
+	return compare(left, right, func(c int) bool { return c <= 0 })
 }
-func graeater(left, right internal.Literal) (internal.Literal, error) {
-	if !((left.IsNumber() && right.IsNumber()) || (left.IsString() && right.IsString())) {
-		return internal.LiteralNil, ErrTypeMissmatch
-	}
 
-	var ret bool
-	if left.IsNumber() {
-		if left.IsInt() && right.IsInt() {
-			ret = left.AsInt() > right.AsInt()
-		} else {
-			ret = left.AsFloat() > right.AsFloat()
-		}
-	} else {
-
-		ret = left.AsString() > right.AsString()
-	}
-
-	return internal.NewLiteralBool(ret), nil
+func graeater(left, right internal.Literal) (internal.Literal, error) {
+	return compare(left, right, func(c int) bool { return c > 0 })
 }
 
 func graeaterOrEqual(left, right internal.Literal) (internal.Literal, error) {
-	if !((left.IsNumber() && right.IsNumber()) || (left.IsString() && right.IsString())) {
-		return internal.LiteralNil, ErrTypeMissmatch
-	}
-
-	var ret bool
-	if left.IsNumber() {
-		if left.IsInt() && right.IsInt() {
-			ret = left.AsInt() >= right.AsInt()
-		} else {
-			ret = left.AsFloat() >= right.AsFloat()
-		}
-	} else {
+	return compare(left, right, func(c int) bool { return c >= 0 })
+}
 
-		ret = left.AsString() >= right.AsString()
+// compare orders numbers or strings; ok tells whether the result of the comparison satisfies the operator.
+// Comparisons with NaN are false.
+func compare(left, right internal.Literal, ok func(c int) bool) (internal.Literal, error) {
+	if left.IsString() && right.IsString() {
+		return internal.NewLiteralBool(ok(strings.Compare(left.AsString(), right.AsString()))), nil
+	}
+	if !left.IsNumber() || !right.IsNumber() {
+		return internal.LiteralNil, ErrTypeMissmatch
 	}
 
-	return internal.NewLiteralBool(ret), nil
+	c, comparable := compareNumbers(left, right)
+	return internal.NewLiteralBool(comparable && ok(c)), nil
 }
+
 func equal(left, right internal.Literal) (internal.Literal, error) {
 	var ret bool
 
 	if left.IsNumber() && right.IsNumber() {
-		if left.IsInt() && right.IsInt() {
-			ret = left.AsInt() == right.AsInt()
-		} else {
-			ret = left.AsFloat() == right.AsFloat()
-		}
+		c, comparable := compareNumbers(left, right)
+		ret = comparable && c == 0
 	} else if left.IsString() && right.IsString() {
 		ret = left.AsString() == right.AsString()
 	} else if left.IsBool() && right.IsBool() {
 		ret = left.AsBool() == right.AsBool()
+	} else if left.IsList() && right.IsList() {
+		ret = left.AsList() == right.AsList()
+	} else if left.IsMap() && right.IsMap() {
+		ret = left.AsMap() == right.AsMap()
 	}
 
 	return internal.NewLiteralBool(ret), nil
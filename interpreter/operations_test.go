@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/token/kind"
 	"github.com/stretchr/testify/require"
 )
 
@@ -145,3 +146,11 @@ func TestTypeMissmatch(t *testing.T) {
 		}
 	}
 }
+
+func TestUnknownOperator(t *testing.T) {
+	_, err := Binary(kind.Dot, internal.NewLiteralInt(1), internal.NewLiteralInt(2))
+	require.EqualError(t, err, "unknown binary operator .")
+
+	_, err = Unary(kind.Plus, internal.NewLiteralInt(1))
+	require.EqualError(t, err, "unknown unary operator +")
+}
@@ -0,0 +1,35 @@
+package interpreter
+
+import (
+	"io"
+	"time"
+)
+
+// Runtime is the state of one execution which is used by native functions; see internal.Runtime.
+// Both backends create it, so natives behave the same way on each of them.
+type Runtime struct {
+	budget *Budget
+	memory *Memory
+	stdin  io.Reader
+}
+
+// NewRuntime creates the runtime of the execution which is limited by the budget and the memory.
+func NewRuntime(budget *Budget, memory *Memory, stdin io.Reader) *Runtime {
+	return &Runtime{budget: budget, memory: memory, stdin: stdin}
+}
+
+func (r *Runtime) Sleep(d time.Duration) error {
+	return r.budget.Sleep(d)
+}
+
+func (r *Runtime) MemoryUsed() int64 {
+	return r.memory.Used()
+}
+
+func (r *Runtime) CheckMemory(n int64) error {
+	return r.memory.Check(n)
+}
+
+func (r *Runtime) Stdin() io.Reader {
+	return r.stdin
+}
@@ -0,0 +1,326 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+// stringMethod is a method of strings; s is the receiver. Positions and lengths are counted in runes.
+type stringMethod struct {
+	params []string
+	// size returns the number of bytes of the result before it is built; it is set for methods
+	// which can build strings much larger than their arguments.
+	size func(s string, args []internal.Literal) (int64, error)
+	call func(s string, args []internal.Literal) (internal.Literal, error)
+}
+
+var stringMethods = map[string]stringMethod{
+	"len": {call: func(s string, _ []internal.Literal) (internal.Literal, error) {
+		return internal.NewLiteralInt(int64(utf8.RuneCountInString(s))), nil
+	}},
+	"upper": {call: stringFunc(strings.ToUpper)},
+	"lower": {call: stringFunc(strings.ToLower)},
+	"trim":  {call: stringFunc(strings.TrimSpace)},
+	"split": {params: []string{"sep"}, call: func(s string, args []internal.Literal) (internal.Literal, error) {
+		sep, err := stringArg("split", "sep", args[0])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return stringList(strings.Split(s, sep)), nil
+	}},
+	"join": {params: []string{"list"}, size: joinSize, call: join},
+	"replace": {params: []string{"old", "new"}, size: func(s string, args []internal.Literal) (int64, error) {
+		old, repl, err := replaceArgs(args)
+		return int64(len(s)) + int64(strings.Count(s, old))*int64(len(repl)-len(old)), err
+	}, call: func(s string, args []internal.Literal) (internal.Literal, error) {
+		old, repl, err := replaceArgs(args)
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return internal.NewLiteralString(strings.ReplaceAll(s, old, repl)), nil
+	}},
+	"contains":   {params: []string{"sub"}, call: stringPredicate("contains", "sub", strings.Contains)},
+	"startsWith": {params: []string{"prefix"}, call: stringPredicate("startsWith", "prefix", strings.HasPrefix)},
+	"endsWith":   {params: []string{"suffix"}, call: stringPredicate("endsWith", "suffix", strings.HasSuffix)},
+	"indexOf": {params: []string{"sub"}, call: func(s string, args []internal.Literal) (internal.Literal, error) {
+		sub, err := stringArg("indexOf", "sub", args[0])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		idx := strings.Index(s, sub)
+		if idx < 0 {
+			return internal.NewLiteralInt(-1), nil
+		}
+
+		return internal.NewLiteralInt(int64(utf8.RuneCountInString(s[:idx]))), nil
+	}},
+	"substr": {params: []string{"start", "end"}, call: substr},
+	"repeat": {params: []string{"n"}, size: func(s string, args []internal.Literal) (int64, error) {
+		n, err := countArg("repeat", "n", args[0])
+		return int64(len(s)) * int64(n), err
+	}, call: func(s string, args []internal.Literal) (internal.Literal, error) {
+		n, err := countArg("repeat", "n", args[0])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return internal.NewLiteralString(strings.Repeat(s, n)), nil
+	}},
+	"padLeft":  {params: []string{"width", "pad"}, size: paddingSize("padLeft"), call: padding("padLeft", true)},
+	"padRight": {params: []string{"width", "pad"}, size: paddingSize("padRight"), call: padding("padRight", false)},
+	"chars": {call: func(s string, _ []internal.Literal) (internal.Literal, error) {
+		chars := make([]string, 0, utf8.RuneCountInString(s))
+		for _, r := range s {
+			chars = append(chars, string(r))
+		}
+
+		return stringList(chars), nil
+	}},
+	"ord": {call: func(s string, _ []internal.Literal) (internal.Literal, error) {
+		if n := utf8.RuneCountInString(s); n != 1 {
+			return internal.LiteralNil, fmt.Errorf("ord expects a single character, got %d", n)
+		}
+
+		r, _ := utf8.DecodeRuneInString(s)
+		return internal.NewLiteralInt(int64(r)), nil
+	}},
+}
+
+// StringMethod returns the method of the string bound to it: `"abc".upper()`.
+// Methods like repeat check the size of the result against the limit of memory of the execution
+// which calls them before they build it; results are limited to MaxInt32 bytes without the limit too.
+func StringMethod(s internal.Literal, name string) (internal.Literal, error) {
+	m, ok := stringMethods[name]
+	if !ok {
+		return internal.LiteralNil, fmt.Errorf("undefined string method: %s", name)
+	}
+
+	return internal.NewLiteralRuntimeFunction(name, m.params, func(rt internal.Runtime, args ...internal.Literal) (internal.Literal, error) {
+		if m.size != nil {
+			size, err := m.size(s.AsString(), args)
+			if err != nil {
+				return internal.LiteralNil, err
+			}
+			if size > math.MaxInt32 {
+				return internal.LiteralNil, fmt.Errorf("result of %s is too large", name)
+			}
+			if err := rt.CheckMemory(size); err != nil {
+				return internal.LiteralNil, err
+			}
+		}
+
+		return m.call(s.AsString(), args)
+	}), nil
+}
+
+// StringMethods returns sorted names of methods of strings.
+func StringMethods() []string {
+	ret := make([]string, 0, len(stringMethods))
+	for name := range stringMethods {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func stringFunc(f func(string) string) func(string, []internal.Literal) (internal.Literal, error) {
+	return func(s string, _ []internal.Literal) (internal.Literal, error) {
+		return internal.NewLiteralString(f(s)), nil
+	}
+}
+
+func stringPredicate(method, param string, f func(s, arg string) bool) func(string, []internal.Literal) (internal.Literal, error) {
+	return func(s string, args []internal.Literal) (internal.Literal, error) {
+		arg, err := stringArg(method, param, args[0])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return internal.NewLiteralBool(f(s, arg)), nil
+	}
+}
+
+// join concatenates strings of the list with the receiver as a separator: `", ".join(["a", "b"])`.
+func join(sep string, args []internal.Literal) (internal.Literal, error) {
+	parts, err := joinArgs(args)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	return internal.NewLiteralString(strings.Join(parts, sep)), nil
+}
+
+// joinSize returns the number of bytes of the string which is built by join.
+func joinSize(sep string, args []internal.Literal) (int64, error) {
+	parts, err := joinArgs(args)
+	if err != nil || len(parts) == 0 {
+		return 0, err
+	}
+
+	size := int64(len(sep)) * int64(len(parts)-1)
+	for _, p := range parts {
+		size += int64(len(p))
+	}
+
+	return size, nil
+}
+
+// joinArgs checks that the argument of join is a list of strings and returns them.
+func joinArgs(args []internal.Literal) ([]string, error) {
+	if !args[0].IsList() {
+		return nil, fmt.Errorf("argument list of join must be a list, got %s", args[0].TypeName())
+	}
+
+	elements := args[0].AsList().Elements
+	parts := make([]string, len(elements))
+	for idx, e := range elements {
+		if !e.IsString() {
+			return nil, fmt.Errorf("element %d of join must be a string, got %s", idx, e.TypeName())
+		}
+		parts[idx] = e.AsString()
+	}
+
+	return parts, nil
+}
+
+func replaceArgs(args []internal.Literal) (string, string, error) {
+	old, err := stringArg("replace", "old", args[0])
+	if err != nil {
+		return "", "", err
+	}
+	repl, err := stringArg("replace", "new", args[1])
+	if err != nil {
+		return "", "", err
+	}
+
+	return old, repl, nil
+}
+
+// substr returns runes from start to end; the bounds are like bounds of slices of lists.
+func substr(s string, args []internal.Literal) (internal.Literal, error) {
+	runes := []rune(s)
+	bounds := [2]int{0, len(runes)}
+	for idx, param := range [2]string{"start", "end"} {
+		if args[idx].IsNil() {
+			continue
+		}
+		if !args[idx].IsInt() {
+			return internal.LiteralNil, fmt.Errorf("argument %s of substr must be an int, got %s", param, args[idx].TypeName())
+		}
+
+		bound, err := sliceBound(args[idx], len(runes))
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+		bounds[idx] = bound
+	}
+	if bounds[0] > bounds[1] {
+		return internal.LiteralNil, fmt.Errorf("invalid substr indices: %d > %d", bounds[0], bounds[1])
+	}
+
+	return internal.NewLiteralString(string(runes[bounds[0]:bounds[1]])), nil
+}
+
+// padding fills the string with pad up to width runes on the left or on the right.
+func padding(method string, left bool) func(string, []internal.Literal) (internal.Literal, error) {
+	return func(s string, args []internal.Literal) (internal.Literal, error) {
+		pad, missing, err := paddingArgs(method, s, args)
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+		if missing <= 0 {
+			return internal.NewLiteralString(s), nil
+		}
+
+		runes := utf8.RuneCountInString(pad)
+		fill := strings.Repeat(pad, missing/runes) + runePrefix(pad, missing%runes)
+		if left {
+			return internal.NewLiteralString(fill + s), nil
+		}
+
+		return internal.NewLiteralString(s + fill), nil
+	}
+}
+
+// paddingSize returns the number of bytes of the string which is built by padding.
+func paddingSize(method string) func(string, []internal.Literal) (int64, error) {
+	return func(s string, args []internal.Literal) (int64, error) {
+		pad, missing, err := paddingArgs(method, s, args)
+		if err != nil || missing <= 0 {
+			return int64(len(s)), err
+		}
+
+		runes := utf8.RuneCountInString(pad)
+		return int64(len(s)) + int64(missing/runes)*int64(len(pad)) + int64(len(runePrefix(pad, missing%runes))), nil
+	}
+}
+
+// paddingArgs checks arguments of padding and returns pad and the number of runes which are missing up to width.
+func paddingArgs(method, s string, args []internal.Literal) (string, int, error) {
+	width, err := countArg(method, "width", args[0])
+	if err != nil {
+		return "", 0, err
+	}
+	pad, err := stringArg(method, "pad", args[1])
+	if err != nil {
+		return "", 0, err
+	}
+	if pad == "" {
+		return "", 0, fmt.Errorf("argument pad of %s must not be empty", method)
+	}
+
+	return pad, width - utf8.RuneCountInString(s), nil
+}
+
+// runePrefix returns the first n runes of s.
+func runePrefix(s string, n int) string {
+	for idx := range s {
+		if n == 0 {
+			return s[:idx]
+		}
+		n--
+	}
+
+	return s
+}
+
+func stringArg(method, param string, v internal.Literal) (string, error) {
+	if !v.IsString() {
+		return "", fmt.Errorf("argument %s of %s must be a string, got %s", param, method, v.TypeName())
+	}
+
+	return v.AsString(), nil
+}
+
+// countArg checks that the argument is a non-negative int.
+func countArg(method, param string, v internal.Literal) (int, error) {
+	if !v.IsInt() {
+		return 0, fmt.Errorf("argument %s of %s must be an int, got %s", param, method, v.TypeName())
+	}
+	if v.AsInt() < 0 {
+		return 0, fmt.Errorf("argument %s of %s must not be negative", param, method)
+	}
+	if v.AsInt() > math.MaxInt32 {
+		return 0, fmt.Errorf("argument %s of %s is too large", param, method)
+	}
+
+	return int(v.AsInt()), nil
+}
+
+func stringList(parts []string) internal.Literal {
+	elements := make([]internal.Literal, len(parts))
+	for idx, p := range parts {
+		elements[idx] = internal.NewLiteralString(p)
+	}
+
+	return internal.NewLiteralList(elements)
+}
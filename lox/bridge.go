@@ -0,0 +1,472 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+var (
+	valueType  = reflect.TypeOf(Value{})
+	bigIntType = reflect.TypeOf(big.Int{})
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ValueOf converts a Go value to a Lox value:
+//   - bools, integers, floats and strings become the same Lox values;
+//     big.Int and integers which do not fit into int64 become bignums;
+//   - slices and arrays become lists and maps become maps, elements are converted recursively;
+//   - structs and pointers to structs become instances with exported fields and methods;
+//     fields are copied, so changes made by scripts are not visible to Go and vice versa;
+//   - functions become native functions; see DefineGo.
+//
+// The field tag `lox:"name"` renames the field and `lox:"-"` hides it.
+func ValueOf(x any) (Value, error) {
+	return valueOf(reflect.ValueOf(x), "")
+}
+
+// DefineGo converts the Go value by ValueOf and defines it as the global variable.
+// A function gets the name of the variable. Arguments of the function are converted
+// to types of its parameters like Decode does; it may return an error as the last result.
+func (r *Runtime) DefineGo(name string, x any) error {
+	v, err := valueOf(reflect.ValueOf(x), name)
+	if err != nil {
+		return err
+	}
+
+	r.Define(name, v)
+	return nil
+}
+
+// Decode stores the Lox value in the value pointed to by ptr.
+// Lists are decoded to slices and arrays, maps to maps, maps and instances to structs
+// and any value to Value. Values decoded to an interface are nil, bool, int64, *big.Int, float64,
+// string, []any and map[any]any; functions, classes and instances stay Value.
+func (v Value) Decode(ptr any) error {
+	p := reflect.ValueOf(ptr)
+	if p.Kind() != reflect.Pointer || p.IsNil() {
+		return errors.New("decode target must be a non-nil pointer")
+	}
+
+	ret, err := convert(v, p.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	p.Elem().Set(ret)
+	return nil
+}
+
+func valueOf(x reflect.Value, name string) (Value, error) {
+	if !x.IsValid() {
+		return Nil(), nil
+	}
+	if x.Type() == valueType {
+		return x.Interface().(Value), nil
+	}
+	if x.Type() == bigIntType {
+		b := x.Interface().(big.Int)
+		return BigInt(new(big.Int).Set(&b)), nil
+	}
+	if x.Kind() == reflect.Pointer && x.Type().Elem() == bigIntType {
+		if x.IsNil() {
+			return Nil(), nil
+		}
+		return BigInt(new(big.Int).Set(x.Interface().(*big.Int))), nil
+	}
+
+	switch x.Kind() {
+	case reflect.Bool:
+		return Bool(x.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(x.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return BigInt(new(big.Int).SetUint64(x.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(x.Float()), nil
+	case reflect.String:
+		return String(x.String()), nil
+	case reflect.Slice, reflect.Array:
+		if x.Kind() == reflect.Slice && x.IsNil() {
+			return Nil(), nil
+		}
+
+		elements := make([]Value, x.Len())
+		for i := range elements {
+			e, err := valueOf(x.Index(i), "")
+			if err != nil {
+				return Nil(), fmt.Errorf("element %d: %w", i, err)
+			}
+			elements[i] = e
+		}
+		return List(elements...), nil
+	case reflect.Map:
+		if x.IsNil() {
+			return Nil(), nil
+		}
+		return mapOf(x)
+	case reflect.Pointer, reflect.Interface:
+		if x.IsNil() {
+			return Nil(), nil
+		}
+		if x.Kind() == reflect.Pointer && x.Elem().Kind() == reflect.Struct {
+			return instanceOf(x)
+		}
+		return valueOf(x.Elem(), name)
+	case reflect.Struct:
+		return instanceOf(x)
+	case reflect.Func:
+		if x.IsNil() {
+			return Nil(), nil
+		}
+		return funcOf(x, name)
+	}
+
+	return Nil(), fmt.Errorf("unsupported type %s", x.Type())
+}
+
+// mapOf converts the Go map; entries are sorted by keys, so the order does not change between runs.
+func mapOf(x reflect.Value) (Value, error) {
+	keys := x.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+
+	entries := make([]Entry, len(keys))
+	for i, k := range keys {
+		key, err := valueOf(k, "")
+		if err != nil {
+			return Nil(), fmt.Errorf("key %v: %w", k, err)
+		}
+		value, err := valueOf(x.MapIndex(k), "")
+		if err != nil {
+			return Nil(), fmt.Errorf("key %v: %w", k, err)
+		}
+		entries[i] = Entry{Key: key, Value: value}
+	}
+
+	return Map(entries...)
+}
+
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
+
+// instanceOf converts the struct or the pointer to the struct. Methods are stored as fields
+// which hold native functions, so they are called with the receiver of the conversion.
+func instanceOf(x reflect.Value) (Value, error) {
+	s := x
+	if s.Kind() == reflect.Pointer {
+		s = s.Elem()
+	}
+
+	class := internal.NewLiteralClass(s.Type().Name(), nil, map[string]internal.Literal{}).AsClass()
+	instance := internal.NewLiteralClassInstance(&class)
+	fields := instance.AsClassInstance()
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Type().Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+
+		v, err := valueOf(s.Field(i), "")
+		if err != nil {
+			return Nil(), fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		fields.Set(name, v.literal())
+	}
+
+	for i := 0; i < x.NumMethod(); i++ {
+		m := x.Type().Method(i)
+		v, err := funcOf(x.Method(i), class.Name+"."+m.Name)
+		if err != nil {
+			return Nil(), fmt.Errorf("method %s: %w", m.Name, err)
+		}
+		fields.Set(m.Name, v.literal())
+	}
+
+	return wrap(instance), nil
+}
+
+// fieldName returns the name of the exported field in Lox.
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+
+	tag, _, _ := strings.Cut(f.Tag.Get("lox"), ",")
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+
+	return tag, true
+}
+
+func funcOf(fn reflect.Value, name string) (Value, error) {
+	t := fn.Type()
+	if t.IsVariadic() {
+		return Nil(), errors.New("variadic functions are not supported")
+	}
+
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+	results := t.NumOut()
+	if returnsError {
+		results--
+	}
+	if results > 1 {
+		return Nil(), fmt.Errorf("function returns %d values; expected at most one value and an error", t.NumOut())
+	}
+
+	if name == "" {
+		name = "<native>"
+	}
+	params := make([]string, t.NumIn())
+	for i := range params {
+		params[i] = fmt.Sprintf("arg%d", i+1)
+	}
+
+	return Func(name, params, func(args ...Value) (Value, error) {
+		in := make([]reflect.Value, len(args))
+		for i, a := range args {
+			v, err := convert(a, t.In(i))
+			if err != nil {
+				return Nil(), fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			in[i] = v
+		}
+
+		out := fn.Call(in)
+		if returnsError {
+			if err := out[len(out)-1]; !err.IsNil() {
+				return Nil(), err.Interface().(error)
+			}
+		}
+		if results == 0 {
+			return Nil(), nil
+		}
+
+		return valueOf(out[0], "")
+	}), nil
+}
+
+// convert returns the Go value of the type t which is equal to the Lox value.
+func convert(v Value, t reflect.Type) (reflect.Value, error) {
+	if t == valueType {
+		return reflect.ValueOf(v), nil
+	}
+
+	ret := reflect.New(t).Elem()
+	k := v.Kind()
+	if t == bigIntType {
+		if k != KindInt && k != KindBigInt {
+			return ret, fmt.Errorf("expected %s but got %s", t, k)
+		}
+		ret.Set(reflect.ValueOf(*v.AsBigInt()))
+		return ret, nil
+	}
+	mismatch := func() error {
+		return fmt.Errorf("expected %s but got %s", t, k)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if k != KindBool {
+			return ret, mismatch()
+		}
+		ret.SetBool(v.AsBool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if k == KindBigInt {
+			return ret, fmt.Errorf("value %s overflows %s", v, t)
+		}
+		if k != KindInt {
+			return ret, mismatch()
+		}
+		if ret.OverflowInt(v.AsInt()) {
+			return ret, fmt.Errorf("value %d overflows %s", v.AsInt(), t)
+		}
+		ret.SetInt(v.AsInt())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if k != KindInt && k != KindBigInt {
+			return ret, mismatch()
+		}
+		b := v.AsBigInt()
+		if b.Sign() < 0 || !b.IsUint64() || ret.OverflowUint(b.Uint64()) {
+			return ret, fmt.Errorf("value %s overflows %s", v, t)
+		}
+		ret.SetUint(b.Uint64())
+	case reflect.Float32, reflect.Float64:
+		if k != KindFloat && k != KindInt && k != KindBigInt {
+			return ret, mismatch()
+		}
+		ret.SetFloat(v.AsFloat())
+	case reflect.String:
+		if k != KindString {
+			return ret, mismatch()
+		}
+		ret.SetString(v.AsString())
+	case reflect.Slice, reflect.Array:
+		if k == KindNil && t.Kind() == reflect.Slice {
+			return ret, nil
+		}
+		if k != KindList {
+			return ret, mismatch()
+		}
+
+		elements := v.AsList()
+		if t.Kind() == reflect.Slice {
+			ret = reflect.MakeSlice(t, len(elements), len(elements))
+		} else if len(elements) != t.Len() {
+			return ret, fmt.Errorf("expected %s but got list of %d elements", t, len(elements))
+		}
+		for i, e := range elements {
+			ev, err := convert(e, t.Elem())
+			if err != nil {
+				return ret, fmt.Errorf("element %d: %w", i, err)
+			}
+			ret.Index(i).Set(ev)
+		}
+	case reflect.Map:
+		if k == KindNil {
+			return ret, nil
+		}
+		if k != KindMap {
+			return ret, mismatch()
+		}
+
+		ret = reflect.MakeMap(t)
+		for _, e := range v.AsMap() {
+			key, err := convert(e.Key, t.Key())
+			if err != nil {
+				return ret, fmt.Errorf("key %s: %w", e.Key, err)
+			}
+			value, err := convert(e.Value, t.Elem())
+			if err != nil {
+				return ret, fmt.Errorf("key %s: %w", e.Key, err)
+			}
+			ret.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		fields, ok := structFields(v)
+		if !ok {
+			return ret, mismatch()
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := fieldName(f)
+			if !ok {
+				continue
+			}
+			fv, ok := fields[name]
+			if !ok {
+				continue
+			}
+
+			value, err := convert(fv, f.Type)
+			if err != nil {
+				return ret, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			ret.Field(i).Set(value)
+		}
+	case reflect.Pointer:
+		if k == KindNil {
+			return ret, nil
+		}
+
+		elem, err := convert(v, t.Elem())
+		if err != nil {
+			return ret, err
+		}
+		ret = reflect.New(t.Elem())
+		ret.Elem().Set(elem)
+	case reflect.Interface:
+		if k == KindNil {
+			return ret, nil
+		}
+
+		natural := naturalOf(v)
+		if !reflect.TypeOf(natural).Implements(t) {
+			return ret, mismatch()
+		}
+		ret.Set(reflect.ValueOf(natural))
+	default:
+		return ret, fmt.Errorf("unsupported type %s", t)
+	}
+
+	return ret, nil
+}
+
+// structFields returns fields of the instance or entries of the map with string keys.
+func structFields(v Value) (map[string]Value, bool) {
+	ret := make(map[string]Value)
+	switch v.Kind() {
+	case KindInstance:
+		for name, f := range v.literal().AsClassInstance().Fields {
+			ret[name] = wrap(f)
+		}
+	case KindMap:
+		for _, e := range v.AsMap() {
+			if e.Key.Kind() == KindString {
+				ret[e.Key.AsString()] = e.Value
+			}
+		}
+	default:
+		return nil, false
+	}
+
+	return ret, true
+}
+
+// naturalOf returns the Go value which is used for the Lox value when the Go type is not known.
+func naturalOf(v Value) any {
+	switch v.Kind() {
+	case KindNil:
+		return nil
+	case KindBool:
+		return v.AsBool()
+	case KindInt:
+		return v.AsInt()
+	case KindBigInt:
+		return v.AsBigInt()
+	case KindFloat:
+		return v.AsFloat()
+	case KindString:
+		return v.AsString()
+	case KindList:
+		elements := v.AsList()
+		ret := make([]any, len(elements))
+		for i, e := range elements {
+			ret[i] = naturalOf(e)
+		}
+		return ret
+	case KindMap:
+		ret := make(map[any]any)
+		for _, e := range v.AsMap() {
+			ret[naturalOf(e.Key)] = naturalOf(e.Value)
+		}
+		return ret
+	}
+
+	return v
+}
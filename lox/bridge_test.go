@@ -0,0 +1,159 @@
+package lox
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X, Y   int
+	Label  string `lox:"label"`
+	Hidden string `lox:"-"`
+	secret int
+}
+
+func (p point) Sum() int {
+	return p.X + p.Y
+}
+
+type counter struct {
+	N int
+}
+
+func (c *counter) Inc(by int) int {
+	c.N += by
+	return c.N
+}
+
+func TestValueOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "nil", value: nil, expected: "nil"},
+		{name: "bool", value: true, expected: "true"},
+		{name: "uint8", value: uint8(7), expected: "7"},
+		{name: "string", value: "s", expected: "s"},
+		{name: "slice", value: []int{1, 2}, expected: "[1, 2]"},
+		{name: "nested", value: [][]string{{"a"}, {}}, expected: "[[a], []]"},
+		{name: "map is sorted by keys", value: map[int]bool{10: true, 9: false}, expected: "{9: false, 10: true}"},
+		{name: "pointer", value: new(int), expected: "0"},
+		{name: "big int", value: new(big.Int).Lsh(big.NewInt(1), 70), expected: "1180591620717411303424"},
+		{name: "small big int", value: *big.NewInt(5), expected: "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ValueOf(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, v.String())
+		})
+	}
+
+	_, err := ValueOf([]chan int{nil})
+	require.EqualError(t, err, "element 0: unsupported type chan int")
+
+	v, err := ValueOf(uint64(1 << 63))
+	require.NoError(t, err)
+	require.Equal(t, KindBigInt, v.Kind())
+	require.Equal(t, "9223372036854775808", v.String())
+}
+
+func TestRuntime_DefineGo(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			r := New(WithBackend(b), WithStderr(io.Discard))
+			require.NoError(t, r.DefineGo("repeat", func(s string, n int) (string, error) {
+				if n < 0 {
+					return "", errors.New("negative count")
+				}
+				return strings.Repeat(s, n), nil
+			}))
+			require.NoError(t, r.DefineGo("total", func(xs []float64) float64 {
+				var ret float64
+				for _, x := range xs {
+					ret += x
+				}
+				return ret
+			}))
+			require.NoError(t, r.DefineGo("p", point{X: 1, Y: 2, Label: "a", Hidden: "h", secret: 3}))
+			require.NoError(t, r.DefineGo("c", &counter{}))
+
+			ret, err := r.RunString(`repeat("ab", 2); total([1, 2.5]); p.X; p.label; p.Sum(); c.Inc(2); c.Inc(3);`)
+			require.NoError(t, err)
+			require.Equal(t, []Value{String("abab"), Float(3.5), Int(1), String("a"), Int(3), Int(2), Int(5)}, ret)
+
+			_, err = r.RunString(`repeat("ab", -1);`)
+			require.EqualError(t, err, "1:7: negative count near 'repeat'")
+
+			_, err = r.RunString(`repeat(1, 2);`)
+			require.EqualError(t, err, "1:7: argument 1: expected string but got int near 'repeat'")
+
+			_, err = r.RunString(`total([1, "a"]);`)
+			require.EqualError(t, err, "1:6: argument 1: element 1: expected float64 but got string near 'total'")
+
+			_, err = r.RunString(`p.Hidden;`)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValue_Decode(t *testing.T) {
+	r := New(WithStderr(io.Discard))
+	_, err := r.RunString(`
+class Point {}
+var p = Point();
+p.X = 1;
+p.label = "a";
+var m = {"X": 2, "Y": 3};
+var xs = [1, 2.5, "s", [true], {"k": nil}];
+`)
+	require.NoError(t, err)
+
+	var p point
+	v, _ := r.Get("p")
+	require.NoError(t, v.Decode(&p))
+	require.Equal(t, point{X: 1, Label: "a"}, p)
+
+	var pp *point
+	v, _ = r.Get("m")
+	require.NoError(t, v.Decode(&pp))
+	require.Equal(t, &point{X: 2, Y: 3}, pp)
+
+	var m map[string]int
+	require.NoError(t, v.Decode(&m))
+	require.Equal(t, map[string]int{"X": 2, "Y": 3}, m)
+
+	var xs []any
+	v, _ = r.Get("xs")
+	require.NoError(t, v.Decode(&xs))
+	require.Equal(t, []any{int64(1), 2.5, "s", []any{true}, map[any]any{"k": nil}}, xs)
+
+	var raw []Value
+	require.NoError(t, v.Decode(&raw))
+	require.Equal(t, Int(1), raw[0])
+
+	var small int8
+	require.EqualError(t, Int(300).Decode(&small), "value 300 overflows int8")
+
+	huge := BigInt(new(big.Int).Lsh(big.NewInt(1), 64))
+	var n int64
+	require.EqualError(t, huge.Decode(&n), "value 18446744073709551616 overflows int64")
+	var u uint64
+	require.NoError(t, BigInt(new(big.Int).SetUint64(1<<63)).Decode(&u))
+	require.Equal(t, uint64(1<<63), u)
+	var b big.Int
+	require.NoError(t, huge.Decode(&b))
+	require.Equal(t, "18446744073709551616", b.String())
+
+	var arr [2]int
+	require.EqualError(t, List(Int(1)).Decode(&arr), "expected [2]int but got list of 1 elements")
+
+	require.EqualError(t, Int(1).Decode(small), "decode target must be a non-nil pointer")
+}
@@ -0,0 +1,295 @@
+// Package lox embeds the Lox interpreter into Go programs.
+//
+//	r := lox.New()
+//	r.Define("greeting", lox.String("hello"))
+//	_, err := r.RunString(`fun greet(name) { return greeting + ", " + name; }`)
+//	v, err := r.Call("greet", lox.String("world"))
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nikgalushko/gan-ilox/compiler"
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/nikgalushko/gan-ilox/modules"
+	"github.com/nikgalushko/gan-ilox/natives"
+	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/resolver"
+	"github.com/nikgalushko/gan-ilox/scanner"
+	"github.com/nikgalushko/gan-ilox/vm"
+)
+
+// Backend executes scripts.
+type Backend int8
+
+const (
+	// Tree is the tree-walking interpreter.
+	Tree Backend = iota
+	// VM compiles scripts to the bytecode and executes it by the virtual machine.
+	VM
+)
+
+// Runtime keeps global variables between runs of scripts.
+// It is not safe for concurrent use.
+type Runtime struct {
+	globals *env.Environment
+	backend Backend
+	stdout  io.Writer
+	stderr  io.Writer
+	stdin   io.Reader
+	ctx     context.Context
+	// maxSteps, maxCallDepth and timeout limit each run; see interpreter.Budget.
+	maxSteps     int
+	maxCallDepth int
+	timeout      time.Duration
+	memoryLimit  int64
+	modulePath   []string
+	modules      *modules.Loader
+}
+
+type Option func(*Runtime)
+
+func WithBackend(b Backend) Option {
+	return func(r *Runtime) {
+		r.backend = b
+	}
+}
+
+// WithStdout sets the writer of print statements; it is os.Stdout by default.
+func WithStdout(w io.Writer) Option {
+	return func(r *Runtime) {
+		r.stdout = w
+	}
+}
+
+// WithStderr sets the stream where uncaught errors of scripts are reported besides being returned;
+// it is os.Stderr by default.
+func WithStderr(w io.Writer) Option {
+	return func(r *Runtime) {
+		r.stderr = w
+	}
+}
+
+// WithStdin sets the input stream which is read by the builtin readLine; it is os.Stdin by default.
+func WithStdin(rd io.Reader) Option {
+	return func(r *Runtime) {
+		r.stdin = rd
+	}
+}
+
+// WithContext stops runs with interpreter.ErrCanceled when ctx is done.
+func WithContext(ctx context.Context) Option {
+	return func(r *Runtime) {
+		r.ctx = ctx
+	}
+}
+
+// WithMaxSteps limits iterations of loops and calls of each run.
+func WithMaxSteps(n int) Option {
+	return func(r *Runtime) {
+		r.maxSteps = n
+	}
+}
+
+// WithMaxCallDepth overrides interpreter.DefaultMaxCallDepth; zero does not limit the depth.
+func WithMaxCallDepth(n int) Option {
+	return func(r *Runtime) {
+		r.maxCallDepth = n
+	}
+}
+
+// WithTimeout limits the duration of each run.
+func WithTimeout(d time.Duration) Option {
+	return func(r *Runtime) {
+		r.timeout = d
+	}
+}
+
+// WithMemoryLimit stops runs with interpreter.ErrMemoryLimit when they allocate more than n bytes.
+// Scripts query allocated bytes by the builtin memoryUsage().
+func WithMemoryLimit(n int64) Option {
+	return func(r *Runtime) {
+		r.memoryLimit = n
+	}
+}
+
+// WithModulePath sets directories where imported modules are searched
+// when they are not found near the importing file.
+func WithModulePath(dirs ...string) Option {
+	return func(r *Runtime) {
+		r.modulePath = dirs
+	}
+}
+
+// New creates a runtime where builtins like len and push are defined; see natives.Builtins.
+// Builtins are also visible to imported modules, while other global variables are not.
+func New(opts ...Option) *Runtime {
+	builtins := env.New()
+	natives.Builtins().Define(builtins)
+	r := &Runtime{
+		globals:      env.NewWithParent(builtins),
+		stdout:       os.Stdout,
+		stderr:       os.Stderr,
+		stdin:        os.Stdin,
+		ctx:          context.Background(),
+		maxCallDepth: interpreter.DefaultMaxCallDepth,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.modules = modules.New(builtins, r.modulePath...)
+	natives.Std().Install(r.modules)
+
+	return r
+}
+
+// RunString executes the source and returns values of its top-level expression statements.
+// Runtime errors are *interpreter.RuntimeError.
+func (r *Runtime) RunString(source string) ([]Value, error) {
+	return r.run(source, "")
+}
+
+// RunFile is like RunString but reads the source from the file;
+// the name of the file is reported in runtime errors.
+func (r *Runtime) RunFile(path string) ([]Value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.run(string(data), path)
+}
+
+// Define creates or replaces the global variable.
+func (r *Runtime) Define(name string, v Value) {
+	r.globals.Define(name, v.literal())
+}
+
+// RegisterModule makes members importable by scripts as the module with the name, e.g. `import "name" as m;`.
+// Modules of the standard library like math are registered by New.
+func (r *Runtime) RegisterModule(name string, members map[string]Value) {
+	m := make(natives.Module, len(members))
+	for k, v := range members {
+		m[k] = v.literal()
+	}
+	natives.Registry{name: m}.Install(r.modules)
+}
+
+func (r *Runtime) Get(name string) (Value, error) {
+	l, err := r.globals.Get(name)
+	if err != nil {
+		return Nil(), fmt.Errorf("%w: %s", err, name)
+	}
+
+	return wrap(l), nil
+}
+
+// Call calls the global function or class with arguments.
+func (r *Runtime) Call(name string, args ...Value) (Value, error) {
+	callee, err := r.Get(name)
+	if err != nil {
+		return Nil(), err
+	}
+	if k := callee.Kind(); k != KindFunction && k != KindClass {
+		return Nil(), errors.New(name + " is not callable")
+	}
+
+	var ret internal.Literal
+	if r.backend == VM {
+		ret, err = r.vm("").Call(callee.literal(), unwrapAll(args))
+	} else {
+		ret, err = r.interpreter(nil, "").Call(callee.literal(), unwrapAll(args))
+	}
+	if err != nil {
+		return Nil(), err
+	}
+
+	return wrap(ret), nil
+}
+
+func (r *Runtime) run(source, filename string) ([]Value, error) {
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := parser.New(tokens).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err = resolver.New().Resolve(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []any
+	if r.backend == VM {
+		script, err := compiler.New().Compile(stmts)
+		if err != nil {
+			return nil, err
+		}
+		ret, err = r.vm(filename).Run(script)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ret, err = r.interpreter(stmts, filename).Interpret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	values := make([]Value, len(ret))
+	for i, v := range ret {
+		values[i] = wrap(v.(internal.Literal))
+	}
+
+	return values, nil
+}
+
+func (r *Runtime) interpreter(stmts []internal.Stmt, filename string) *interpreter.Interpreter {
+	return interpreter.New(r.globals, stmts,
+		interpreter.WithFile(filename),
+		interpreter.WithStdout(r.stdout),
+		interpreter.WithStderr(r.stderr),
+		interpreter.WithStdin(r.stdin),
+		interpreter.WithContext(r.ctx),
+		interpreter.WithMaxSteps(r.maxSteps),
+		interpreter.WithMaxCallDepth(r.maxCallDepth),
+		interpreter.WithDeadline(r.deadline()),
+		interpreter.WithMemoryLimit(r.memoryLimit),
+		interpreter.WithModules(r.modules),
+	)
+}
+
+func (r *Runtime) vm(filename string) *vm.VM {
+	return vm.New(r.globals,
+		vm.WithFile(filename),
+		vm.WithStdout(r.stdout),
+		vm.WithStderr(r.stderr),
+		vm.WithStdin(r.stdin),
+		vm.WithContext(r.ctx),
+		vm.WithMaxSteps(r.maxSteps),
+		vm.WithMaxCallDepth(r.maxCallDepth),
+		vm.WithDeadline(r.deadline()),
+		vm.WithMemoryLimit(r.memoryLimit),
+		vm.WithModules(r.modules),
+	)
+}
+
+// deadline returns the end of the run which starts now; it is zero without the timeout.
+func (r *Runtime) deadline() time.Time {
+	if r.timeout == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(r.timeout)
+}
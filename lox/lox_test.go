@@ -0,0 +1,281 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikgalushko/gan-ilox/internal/programtest"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/stretchr/testify/require"
+)
+
+var backends = map[string]Backend{"tree": Tree, "vm": VM}
+
+func TestRuntime_RunString(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			r := New(WithBackend(b), WithStderr(io.Discard))
+			r.Define("greeting", String("hello"))
+
+			ret, err := r.RunString(`var who = "world"; greeting + ", " + who;`)
+			require.NoError(t, err)
+			require.Equal(t, []Value{String("hello, world")}, ret)
+
+			// globals are kept between runs
+			v, err := r.Get("who")
+			require.NoError(t, err)
+			require.Equal(t, "world", v.AsString())
+		})
+	}
+}
+
+func TestRuntime_RunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.lox")
+	require.NoError(t, os.WriteFile(path, []byte("var a = 1;\na.b;\n"), 0o600))
+
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(WithBackend(b), WithStderr(io.Discard)).RunFile(path)
+
+			var rErr *interpreter.RuntimeError
+			require.ErrorAs(t, err, &rErr)
+			require.Equal(t, path, rErr.File)
+			require.Equal(t, 2, rErr.Line)
+		})
+	}
+}
+
+func TestRuntime_Modules(t *testing.T) {
+	dir, lib := t.TempDir(), t.TempDir()
+	main := filepath.Join(dir, "main.lox")
+	broken := filepath.Join(lib, "broken.lox")
+	require.NoError(t, os.WriteFile(main, []byte("import \"util.lox\" as u;\nu.fail();\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(lib, "util.lox"), []byte("fun fail() {\n\treturn nil + 1;\n}\n"), 0o600))
+	require.NoError(t, os.WriteFile(broken, []byte("var a = 1;\nimport \"missing.lox\" as m;\n"), 0o600))
+
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(WithBackend(b), WithStderr(io.Discard)).RunFile(main)
+			require.EqualError(t, err, main+":1:1: cannot find module util.lox near 'import'")
+
+			_, err = New(WithBackend(b), WithStderr(io.Discard), WithModulePath(lib)).RunFile(main)
+			var rErr *interpreter.RuntimeError
+			require.ErrorAs(t, err, &rErr)
+			require.Equal(t, filepath.Join(lib, "util.lox")+":2:13: type missmatch near '+'\n"+
+				"\tat fail ("+filepath.Join(lib, "util.lox")+":2:13)\n"+
+				"\tat <script> ("+main+":2:7)", rErr.Trace())
+
+			_, err = New(WithBackend(b), WithStderr(io.Discard), WithModulePath(lib)).RunString(`import "broken.lox" as b;`)
+			require.EqualError(t, err, broken+":2:1: cannot find module missing.lox near 'import'")
+		})
+	}
+}
+
+func TestRuntime_MathModule(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			r := New(WithBackend(b), WithStderr(io.Discard))
+			r.RegisterModule("config", map[string]Value{"scale": Int(3)})
+
+			ret, err := r.RunString(`import "math" as m; from "config" import scale;
+m.sqrt(16); m.max(1, 2.5); m.abs(-3) * scale; m.floor(m.pi);`)
+			require.NoError(t, err)
+			require.Equal(t, []Value{Float(4), Float(2.5), Int(9), Float(3)}, ret)
+
+			_, err = r.RunString(`import "math" as m; m.sqrt("4");`)
+			require.EqualError(t, err, "1:27: argument x of sqrt must be a number, got string near 'sqrt'")
+		})
+	}
+}
+
+func TestRuntime_Call(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			r := New(WithBackend(b), WithStderr(io.Discard))
+			r.Define("double", Func("double", []string{"x"}, func(args ...Value) (Value, error) {
+				return Int(args[0].AsInt() * 2), nil
+			}))
+			_, err := r.RunString(`
+fun add(a, b) { return double(a) + b; }
+fun counter() {
+	var i = 0;
+	fun next() { i = i + 1; return i; }
+	return next;
+}
+var next = counter();
+class Point {
+	init(x) { this.x = x; }
+}
+`)
+			require.NoError(t, err)
+
+			ret, err := r.Call("add", Int(2), Int(1))
+			require.NoError(t, err)
+			require.Equal(t, Int(5), ret)
+
+			for i := int64(1); i <= 2; i++ {
+				ret, err = r.Call("next")
+				require.NoError(t, err)
+				require.Equal(t, Int(i), ret)
+			}
+
+			ret, err = r.Call("Point", Int(3))
+			require.NoError(t, err)
+			require.Equal(t, KindInstance, ret.Kind())
+
+			ret, err = r.Call("double", Float(1.5))
+			require.NoError(t, err)
+			require.Equal(t, Int(2), ret)
+
+			_, err = r.Call("add", Int(1))
+			require.EqualError(t, err, "expected 2 arguments but got 1")
+
+			_, err = r.Call("missing")
+			require.EqualError(t, err, "undefined variable: missing")
+
+			r.Define("n", Int(1))
+			_, err = r.Call("n")
+			require.EqualError(t, err, "n is not callable")
+		})
+	}
+}
+
+func TestRuntime_FuncError(t *testing.T) {
+	errFailed := errors.New("failed")
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			r := New(WithBackend(b), WithStderr(io.Discard))
+			r.Define("fail", Func("fail", nil, func(args ...Value) (Value, error) {
+				return Nil(), errFailed
+			}))
+
+			_, err := r.RunString(`fail();`)
+			require.ErrorIs(t, err, errFailed)
+			require.EqualError(t, err, "1:5: failed near 'fail'")
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	require.True(t, Value{}.IsNil())
+	require.Equal(t, KindNil, Value{}.Kind())
+	require.Equal(t, "nil", Value{}.String())
+
+	list := List(Int(1), String("a"))
+	require.Equal(t, KindList, list.Kind())
+	require.Equal(t, []Value{Int(1), String("a")}, list.AsList())
+	require.Equal(t, "[1, a]", list.String())
+
+	m, err := Map(Entry{Key: String("a"), Value: Bool(true)}, Entry{Key: Int(1), Value: Nil()})
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{Key: String("a"), Value: Bool(true)}, {Key: Int(1), Value: Nil()}}, m.AsMap())
+
+	_, err = Map(Entry{Key: list, Value: Nil()})
+	require.EqualError(t, err, "map key must be a string, a number or a bool")
+
+	require.Nil(t, Int(1).AsList())
+	require.Equal(t, "float", Float(1).Kind().String())
+
+	require.Equal(t, KindInt, BigInt(big.NewInt(7)).Kind())
+	huge := BigInt(new(big.Int).Lsh(big.NewInt(1), 64))
+	require.Equal(t, "bigint", huge.Kind().String())
+	require.Equal(t, "18446744073709551616", huge.AsBigInt().String())
+	require.Equal(t, int64(0), huge.AsInt())
+}
+
+func TestRuntime_Stdout(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			_, err := New(WithBackend(b), WithStdout(&out)).RunString(`print 1; print [2, "a"];`)
+			require.NoError(t, err)
+			require.Equal(t, "1\n[2, a]\n", out.String())
+		})
+	}
+}
+
+func TestRuntime_Streams(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			r := New(WithBackend(b), WithStdin(strings.NewReader("first\r\nsecond")), WithStderr(&stderr))
+
+			// nil at the end of the input is not a result
+			ret, err := r.RunString(`readLine(); readLine(); readLine();`)
+			require.NoError(t, err)
+			require.Equal(t, []Value{String("first"), String("second")}, ret)
+			require.Empty(t, stderr.String())
+
+			_, err = r.RunString(`fun f() { return nil + 1; } f();`)
+			require.Error(t, err)
+			require.Equal(t, "1:22: type missmatch near '+'\n\tat f (1:22)\n\tat <script> (1:30)\n", stderr.String())
+		})
+	}
+}
+
+func TestRuntime_Builtins(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			ret, err := New(WithBackend(b), WithStderr(io.Discard)).RunString(`
+			var xs = [1];
+			push(xs, 2);
+			var m = {"a": 1};
+			delete(m, "a");
+			len(xs);
+			pop(xs);
+			has(m, "a");
+			`)
+			require.NoError(t, err)
+			require.Equal(t, []Value{Bool(true), Int(2), Int(2), Bool(false)}, ret)
+		})
+	}
+}
+
+func TestRuntime_Limits(t *testing.T) {
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(WithBackend(b), WithStderr(io.Discard), WithMaxSteps(10)).RunString(`for {}`)
+			require.ErrorIs(t, err, interpreter.ErrBudgetExceeded)
+
+			for _, code := range programtest.Unbounded {
+				_, err = New(WithBackend(b), WithStderr(io.Discard), WithMemoryLimit(1<<20)).RunString(code)
+				require.ErrorIs(t, err, interpreter.ErrMemoryLimit, code)
+				require.ErrorContains(t, err, "memory limit exceeded: ", code)
+			}
+
+			for _, code := range programtest.Oversized {
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				_, err = New(WithBackend(b), WithStderr(io.Discard), WithMemoryLimit(1<<20)).RunString(code)
+				runtime.ReadMemStats(&after)
+				require.ErrorIs(t, err, interpreter.ErrMemoryLimit, code)
+				require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(16<<20), code)
+			}
+
+			_, err = New(WithBackend(b), WithStderr(io.Discard), WithTimeout(time.Millisecond)).RunString(`for {}`)
+			require.ErrorIs(t, err, interpreter.ErrBudgetExceeded)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = New(WithBackend(b), WithStderr(io.Discard), WithContext(ctx)).RunString(`for {}`)
+			require.ErrorIs(t, err, interpreter.ErrCanceled)
+
+			// sleep is interrupted like loops and can not be caught either
+			start := time.Now()
+			_, err = New(WithBackend(b), WithStderr(io.Discard), WithTimeout(100*time.Millisecond)).RunString(`try { sleep(3); } catch (e) {}`)
+			require.ErrorIs(t, err, interpreter.ErrBudgetExceeded)
+			_, err = New(WithBackend(b), WithStderr(io.Discard), WithContext(ctx)).RunString(`sleep(3);`)
+			require.ErrorIs(t, err, interpreter.ErrCanceled)
+			require.Less(t, time.Since(start), time.Second)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package lox
+
+import (
+	"math/big"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+type Kind int8
+
+const (
+	KindNil Kind = iota
+	KindBool
+	KindInt
+	KindFloat
+	KindString
+	KindList
+	KindMap
+	KindFunction
+	KindClass
+	KindInstance
+	// KindBigInt is an integer which does not fit into int64.
+	KindBigInt
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNil:
+		return "nil"
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindList:
+		return "list"
+	case KindMap:
+		return "map"
+	case KindFunction:
+		return "function"
+	case KindClass:
+		return "class"
+	case KindInstance:
+		return "instance"
+	case KindBigInt:
+		return "bigint"
+	}
+
+	return "unknown"
+}
+
+// Value is a Lox value which is passed between a host program and scripts.
+// The zero Value is nil.
+type Value struct {
+	l internal.Literal
+	// valid is false for the zero Value, so it is nil without the initialization of the literal.
+	valid bool
+}
+
+// Entry is a key and a value of a map.
+type Entry struct {
+	Key   Value
+	Value Value
+}
+
+func Nil() Value {
+	return wrap(internal.LiteralNil)
+}
+
+func Bool(b bool) Value {
+	return wrap(internal.NewLiteralBool(b))
+}
+
+func Int(i int64) Value {
+	return wrap(internal.NewLiteralInt(i))
+}
+
+// BigInt returns the integer; it is KindInt if it fits into int64. b must not be modified after the call.
+func BigInt(b *big.Int) Value {
+	return wrap(internal.NewLiteralBigInt(b))
+}
+
+func Float(f float64) Value {
+	return wrap(internal.NewLiteralFloat(f))
+}
+
+func String(s string) Value {
+	return wrap(internal.NewLiteralString(s))
+}
+
+// List returns a new list; scripts see changes of the list through all its copies.
+func List(elements ...Value) Value {
+	return wrap(internal.NewLiteralList(unwrapAll(elements)))
+}
+
+// Map returns a new map with entries in the given order.
+// Keys must be strings, numbers or bools.
+func Map(entries ...Entry) (Value, error) {
+	m := internal.NewMap()
+	for _, e := range entries {
+		if err := m.Set(e.Key.literal(), e.Value.literal()); err != nil {
+			return Nil(), err
+		}
+	}
+
+	return wrap(internal.NewLiteralMap(m)), nil
+}
+
+// Func returns a native function which can be called by scripts.
+// Scripts must pass exactly one argument per parameter.
+func Func(name string, params []string, fn func(args ...Value) (Value, error)) Value {
+	return wrap(internal.NewLiteralNativeFunction(name, params, func(args ...internal.Literal) (internal.Literal, error) {
+		ret, err := fn(wrapAll(args)...)
+		return ret.literal(), err
+	}))
+}
+
+func (v Value) Kind() Kind {
+	l := v.literal()
+	switch {
+	case l.IsBool():
+		return KindBool
+	case l.IsInt():
+		return KindInt
+	case l.IsBigInt():
+		return KindBigInt
+	case l.IsFloat():
+		return KindFloat
+	case l.IsString():
+		return KindString
+	case l.IsList():
+		return KindList
+	case l.IsMap():
+		return KindMap
+	case l.IsFunction():
+		return KindFunction
+	case l.IsClass():
+		return KindClass
+	case l.IsClassInstance():
+		return KindInstance
+	}
+
+	return KindNil
+}
+
+func (v Value) IsNil() bool {
+	return v.Kind() == KindNil
+}
+
+// AsBool returns the truthiness of the value like the condition of if statement.
+func (v Value) AsBool() bool {
+	return v.literal().AsBool()
+}
+
+// AsInt returns the integer; floats are truncated and bignums are reduced to their low 64 bits.
+func (v Value) AsInt() int64 {
+	return v.literal().AsInt()
+}
+
+// AsBigInt returns a copy of the integer; floats are truncated.
+func (v Value) AsBigInt() *big.Int {
+	return new(big.Int).Set(v.literal().AsBigInt())
+}
+
+func (v Value) AsFloat() float64 {
+	return v.literal().AsFloat()
+}
+
+func (v Value) AsString() string {
+	return v.literal().AsString()
+}
+
+// AsList returns a copy of elements of the list or nil if the value is not a list.
+func (v Value) AsList() []Value {
+	if v.Kind() != KindList {
+		return nil
+	}
+
+	return wrapAll(v.literal().AsList().Elements)
+}
+
+// AsMap returns entries of the map in the order of insertion or nil if the value is not a map.
+func (v Value) AsMap() []Entry {
+	if v.Kind() != KindMap {
+		return nil
+	}
+
+	m := v.literal().AsMap()
+	keys, values := m.Keys(), m.Values()
+	ret := make([]Entry, len(keys))
+	for i := range keys {
+		ret[i] = Entry{Key: wrap(keys[i]), Value: wrap(values[i])}
+	}
+
+	return ret
+}
+
+// String formats the value like print statement does.
+func (v Value) String() string {
+	return v.literal().String()
+}
+
+func wrap(l internal.Literal) Value {
+	return Value{l: l, valid: true}
+}
+
+func (v Value) literal() internal.Literal {
+	if !v.valid {
+		return internal.LiteralNil
+	}
+
+	return v.l
+}
+
+func wrapAll(literals []internal.Literal) []Value {
+	ret := make([]Value, len(literals))
+	for i, l := range literals {
+		ret[i] = wrap(l)
+	}
+
+	return ret
+}
+
+func unwrapAll(values []Value) []internal.Literal {
+	ret := make([]internal.Literal, len(values))
+	for i, v := range values {
+		ret[i] = v.literal()
+	}
+
+	return ret
+}
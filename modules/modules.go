@@ -0,0 +1,174 @@
+// Package modules finds, loads and caches modules which are imported by scripts.
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/resolver"
+	"github.com/nikgalushko/gan-ilox/scanner"
+)
+
+// Runner executes statements of the module; globals is the top-level environment of the module.
+type Runner func(file string, stmts []internal.Stmt, globals *env.Environment) error
+
+// Loader executes each module once and keeps its exports: variables which are defined at its top level.
+// Exports are live: assignments to top-level variables of the module are visible through the module value.
+// It is not safe for concurrent use.
+type Loader struct {
+	// Path is a list of directories where modules are searched
+	// when they are not found near the importing file.
+	Path []string
+	// builtins is the parent of the top-level environment of each module;
+	// globals of importing scripts are not visible to modules.
+	builtins *env.Environment
+	// exports are modules which are loaded by absolute paths.
+	exports map[string]internal.Literal
+	// natives are modules which are implemented in Go; they are imported by names.
+	natives map[string]internal.Literal
+	// files are names of modules by their top-level environments.
+	files map[*env.Environment]string
+	// loading are files of modules which are being executed; the last one is the innermost import.
+	loading []string
+}
+
+// New creates a loader of modules which see variables of builtins only.
+func New(builtins *env.Environment, path ...string) *Loader {
+	return &Loader{
+		Path:     path,
+		builtins: builtins,
+		exports:  make(map[string]internal.Literal),
+		natives:  make(map[string]internal.Literal),
+		files:    make(map[*env.Environment]string),
+	}
+}
+
+// Import returns the module which is imported by the file importer; importer is empty for a script without a file.
+// The module is executed by run when it is imported for the first time.
+func (l *Loader) Import(importer, path string, run Runner) (internal.Literal, error) {
+	if m, ok := l.natives[path]; ok {
+		return m, nil
+	}
+
+	file, err := l.find(importer, path)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	key, err := filepath.Abs(file)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+	if m, ok := l.exports[key]; ok {
+		return m, nil
+	}
+
+	for idx, f := range l.loading {
+		if f == key {
+			var cycle []string
+			for _, f := range l.loading[idx:] {
+				cycle = append(cycle, filepath.Base(f))
+			}
+			return internal.LiteralNil, errors.New("import cycle: " + strings.Join(append(cycle, filepath.Base(key)), " -> "))
+		}
+	}
+
+	stmts, err := parse(file)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	globals := env.NewWithParent(l.builtins)
+	l.files[globals] = file
+	l.loading = append(l.loading, key)
+	err = run(file, stmts, globals)
+	l.loading = l.loading[:len(l.loading)-1]
+	if err != nil {
+		delete(l.files, globals)
+		return internal.LiteralNil, err
+	}
+
+	m := internal.NewLiteralClassInstanceWithFields(&internal.Class{Name: path}, globals.Variables())
+	l.exports[key] = m
+
+	return m, nil
+}
+
+// Register makes the native module importable by the name; it takes precedence over files.
+func (l *Loader) Register(name string, members map[string]internal.Literal) {
+	l.natives[name] = module(name, members)
+}
+
+func module(name string, members map[string]internal.Literal) internal.Literal {
+	ret := internal.NewLiteralClassInstance(&internal.Class{Name: name})
+	for k, v := range members {
+		ret.AsClassInstance().Set(k, v)
+	}
+
+	return ret
+}
+
+// File returns the name of the module which top-level environment is globals.
+func (l *Loader) File(globals *env.Environment) (string, bool) {
+	file, ok := l.files[globals]
+	return file, ok
+}
+
+// Export returns the variable which is defined at the top level of the module.
+func Export(module internal.Literal, name string) (internal.Literal, error) {
+	v, ok := module.AsClassInstance().Fields[name]
+	if !ok {
+		return internal.LiteralNil, fmt.Errorf("module %s has no export %s", module.AsClassInstance().Class.Name, name)
+	}
+
+	return v, nil
+}
+
+// find looks for the module near the importing file and then in directories of the search path.
+func (l *Loader) find(importer, path string) (string, error) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		candidates = []string{filepath.Join(filepath.Dir(importer), path)}
+		for _, dir := range l.Path {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return c, nil
+		}
+	}
+
+	return "", fmt.Errorf("cannot find module %s", path)
+}
+
+func parse(file string) ([]internal.Stmt, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := scanner.NewScanner(string(data)).ScanTokens()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	stmts, err := parser.New(tokens).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	stmts, err = resolver.New().Resolve(stmts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return stmts, nil
+}
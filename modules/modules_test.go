@@ -0,0 +1,71 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Import(t *testing.T) {
+	dir, lib := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.lox"), []byte(`var a = 1;`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(lib, "b.lox"), []byte(`var b = 2;`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(lib, "bad.lox"), []byte(`var = 2;`), 0o600))
+
+	builtins := env.New()
+	l := New(builtins, lib)
+	var runs []string
+	run := func(file string, stmts []internal.Stmt, m *env.Environment) error {
+		runs = append(runs, file)
+		m.Define(stmts[0].(internal.VarStmt).Name, internal.NewLiteralInt(int64(len(runs))))
+
+		file, ok := l.File(m)
+		require.True(t, ok)
+		require.Equal(t, runs[len(runs)-1], file)
+		require.Equal(t, builtins, m.Parent())
+		return nil
+	}
+
+	importer := filepath.Join(dir, "main.lox")
+	a, err := l.Import(importer, "a.lox", run)
+	require.NoError(t, err)
+	v, err := Export(a, "a")
+	require.NoError(t, err)
+	require.Equal(t, internal.NewLiteralInt(1), v)
+
+	b, err := l.Import(importer, "b.lox", run)
+	require.NoError(t, err)
+	_, err = Export(b, "a")
+	require.EqualError(t, err, "module b.lox has no export a")
+
+	// the module is executed once
+	_, err = l.Import(importer, "a.lox", run)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "a.lox"), filepath.Join(lib, "b.lox")}, runs)
+
+	_, err = l.Import(importer, "c.lox", run)
+	require.EqualError(t, err, "cannot find module c.lox")
+
+	_, err = l.Import(importer, "bad.lox", run)
+	require.EqualError(t, err, filepath.Join(lib, "bad.lox")+": expect variable name")
+}
+
+func TestLoader_Cycle(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.lox"), []byte(`import "b.lox" as b;`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.lox"), []byte(`import "a.lox" as a;`), 0o600))
+
+	l := New(env.New())
+	var run Runner
+	run = func(file string, stmts []internal.Stmt, m *env.Environment) error {
+		_, err := l.Import(file, stmts[0].(internal.ImportStmt).Path, run)
+		return err
+	}
+
+	_, err := l.Import(filepath.Join(dir, "main.lox"), "a.lox", run)
+	require.EqualError(t, err, "import cycle: a.lox -> b.lox -> a.lox")
+}
@@ -0,0 +1,144 @@
+package natives
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+)
+
+// Builtins returns global functions which scripts call without imports: len, push, pop, has, keys,
+// values and delete of collections, now and sleep, memoryUsage and readLine of the runtime.
+func Builtins() Module {
+	return Module{
+		"len":    internal.NewLiteralNativeFunction("len", []string{"value"}, length),
+		"push":   internal.NewLiteralNativeFunction("push", []string{"list", "value"}, push),
+		"pop":    internal.NewLiteralNativeFunction("pop", []string{"list"}, pop),
+		"has":    internal.NewLiteralNativeFunction("has", []string{"map", "key"}, has),
+		"keys":   internal.NewLiteralNativeFunction("keys", []string{"map"}, keys),
+		"values": internal.NewLiteralNativeFunction("values", []string{"map"}, values),
+		"delete": internal.NewLiteralNativeFunction("delete", []string{"map", "key"}, remove),
+		"now":    internal.NewLiteralNativeFunction("now", nil, now),
+		"sleep":  internal.NewLiteralRuntimeFunction("sleep", []string{"seconds"}, sleep),
+
+		interpreter.MemoryUsageBuiltin: interpreter.NativeMemoryUsage(),
+		interpreter.ReadLineBuiltin:    interpreter.NativeReadLine(),
+	}
+}
+
+// Define makes members of the module global variables of the environment.
+func (m Module) Define(e *env.Environment) {
+	for name, v := range m {
+		e.Define(name, v)
+	}
+}
+
+// length returns the number of elements of the list, entries of the map or characters of the string.
+func length(args ...internal.Literal) (internal.Literal, error) {
+	switch v := args[0]; {
+	case v.IsList():
+		return internal.NewLiteralInt(int64(len(v.AsList().Elements))), nil
+	case v.IsMap():
+		return internal.NewLiteralInt(int64(v.AsMap().Len())), nil
+	case v.IsString():
+		return internal.NewLiteralInt(int64(len([]rune(v.AsString())))), nil
+	}
+
+	return internal.LiteralNil, fmt.Errorf("argument value of len must be a list, a map or a string, got %s", args[0].TypeName())
+}
+
+func push(args ...internal.Literal) (internal.Literal, error) {
+	list, err := listArg("push", args[0])
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	list.Elements = append(list.Elements, args[1])
+	return internal.LiteralNil, nil
+}
+
+func pop(args ...internal.Literal) (internal.Literal, error) {
+	list, err := listArg("pop", args[0])
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+	if len(list.Elements) == 0 {
+		return internal.LiteralNil, errors.New("pop from empty list")
+	}
+
+	ret := list.Elements[len(list.Elements)-1]
+	list.Elements = list.Elements[:len(list.Elements)-1]
+	return ret, nil
+}
+
+func has(args ...internal.Literal) (internal.Literal, error) {
+	m, err := mapArg("has", args[0])
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	ok, err := m.Has(args[1])
+	return internal.NewLiteralBool(ok), err
+}
+
+func keys(args ...internal.Literal) (internal.Literal, error) {
+	m, err := mapArg("keys", args[0])
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	return internal.NewLiteralList(m.Keys()), nil
+}
+
+func values(args ...internal.Literal) (internal.Literal, error) {
+	m, err := mapArg("values", args[0])
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	return internal.NewLiteralList(m.Values()), nil
+}
+
+// remove deletes the key from the map and reports whether the map had it.
+func remove(args ...internal.Literal) (internal.Literal, error) {
+	m, err := mapArg("delete", args[0])
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+
+	ok, err := m.Delete(args[1])
+	return internal.NewLiteralBool(ok), err
+}
+
+// now returns the current time in milliseconds since the Unix epoch.
+func now(args ...internal.Literal) (internal.Literal, error) {
+	return internal.NewLiteralInt(time.Now().UnixMilli()), nil
+}
+
+// sleep waits for the seconds; the wait is interrupted like loops when the execution is canceled or runs out of time.
+func sleep(rt internal.Runtime, args ...internal.Literal) (internal.Literal, error) {
+	if _, err := number("sleep", "seconds", args[0]); err != nil {
+		return internal.LiteralNil, err
+	}
+
+	return internal.LiteralNil, rt.Sleep(time.Duration(args[0].AsInt()) * time.Second)
+}
+
+func listArg(function string, v internal.Literal) (*internal.List, error) {
+	if !v.IsList() {
+		return nil, fmt.Errorf("argument list of %s must be a list, got %s", function, v.TypeName())
+	}
+
+	return v.AsList(), nil
+}
+
+func mapArg(function string, v internal.Literal) (*internal.Map, error) {
+	if !v.IsMap() {
+		return nil, fmt.Errorf("argument map of %s must be a map, got %s", function, v.TypeName())
+	}
+
+	return v.AsMap(), nil
+}
@@ -0,0 +1,51 @@
+package natives
+
+import (
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltins(t *testing.T) {
+	var (
+		i = internal.NewLiteralInt
+		s = internal.NewLiteralString
+	)
+	m := internal.NewMap()
+	require.NoError(t, m.Set(s("a"), i(1)))
+
+	tests := []struct {
+		name     string
+		args     []internal.Literal
+		expected internal.Literal
+		err      string
+	}{
+		{name: "len", args: []internal.Literal{internal.NewLiteralList([]internal.Literal{i(1), i(2)})}, expected: i(2)},
+		{name: "len", args: []internal.Literal{internal.NewLiteralMap(m)}, expected: i(1)},
+		{name: "len", args: []internal.Literal{s("héllo")}, expected: i(5)},
+		{name: "has", args: []internal.Literal{internal.NewLiteralMap(m), s("a")}, expected: internal.NewLiteralBool(true)},
+		{name: "keys", args: []internal.Literal{internal.NewLiteralMap(m)}, expected: internal.NewLiteralList([]internal.Literal{s("a")})},
+		{name: "len", args: []internal.Literal{i(1)}, err: "argument value of len must be a list, a map or a string, got int"},
+		{name: "push", args: []internal.Literal{s("a"), i(1)}, err: "argument list of push must be a list, got string"},
+		{name: "pop", args: []internal.Literal{internal.NewLiteralList(nil)}, err: "pop from empty list"},
+		{name: "delete", args: []internal.Literal{internal.LiteralNil, i(1)}, err: "argument map of delete must be a map, got nil"},
+		{name: "sleep", args: []internal.Literal{s("1")}, err: "argument seconds of sleep must be a number, got string"},
+	}
+
+	builtins := Builtins()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := builtins[tt.name].AsFunction()
+			require.Len(t, f.ArgumentsName, len(tt.args))
+
+			ret, err := f.Call(tt.args, nil)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, ret)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package natives
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/nikgalushko/gan-ilox/token/kind"
+)
+
+// Math returns the math module. Integers stay integers where the result is exact:
+// abs, floor, ceil, round, min, max and pow of integers return integers; other functions return floats.
+func Math() Module {
+	return Module{
+		"pi": internal.NewLiteralFloat(math.Pi),
+		"e":  internal.NewLiteralFloat(math.E),
+
+		"sqrt":  float1("sqrt", math.Sqrt),
+		"sin":   float1("sin", math.Sin),
+		"cos":   float1("cos", math.Cos),
+		"tan":   float1("tan", math.Tan),
+		"asin":  float1("asin", math.Asin),
+		"acos":  float1("acos", math.Acos),
+		"atan":  float1("atan", math.Atan),
+		"log":   float1("log", math.Log),
+		"log2":  float1("log2", math.Log2),
+		"log10": float1("log10", math.Log10),
+		"exp":   float1("exp", math.Exp),
+		"atan2": float2("atan2", "y", "x", math.Atan2),
+
+		"floor": rounding("floor", math.Floor),
+		"ceil":  rounding("ceil", math.Ceil),
+		"round": rounding("round", math.Round),
+
+		"abs": number1("abs", func(x internal.Literal) (internal.Literal, error) {
+			switch {
+			case x.IsFloat():
+				return internal.NewLiteralFloat(math.Abs(x.AsFloat())), nil
+			case x.AsBigInt().Sign() < 0:
+				return interpreter.Unary(kind.Minus, x)
+			}
+
+			return x, nil
+		}),
+		"pow": number2("pow", "x", "y", func(x, y internal.Literal) (internal.Literal, error) {
+			return interpreter.Binary(kind.StarStar, x, y)
+		}),
+		"min": number2("min", "a", "b", func(a, b internal.Literal) (internal.Literal, error) {
+			return choose(a, b, kind.Less)
+		}),
+		"max": number2("max", "a", "b", func(a, b internal.Literal) (internal.Literal, error) {
+			return choose(a, b, kind.Greater)
+		}),
+
+		"isNaN": number1("isNaN", func(x internal.Literal) (internal.Literal, error) {
+			return internal.NewLiteralBool(x.IsFloat() && math.IsNaN(x.AsFloat())), nil
+		}),
+		"isInf": number1("isInf", func(x internal.Literal) (internal.Literal, error) {
+			return internal.NewLiteralBool(x.IsFloat() && math.IsInf(x.AsFloat(), 0)), nil
+		}),
+
+		"int":   internal.NewLiteralNativeFunction("int", []string{"x"}, toInt),
+		"float": internal.NewLiteralNativeFunction("float", []string{"x"}, toFloat),
+	}
+}
+
+// number1 is the function of one number.
+func number1(name string, f func(x internal.Literal) (internal.Literal, error)) internal.Literal {
+	return internal.NewLiteralNativeFunction(name, []string{"x"}, func(args ...internal.Literal) (internal.Literal, error) {
+		x, err := number(name, "x", args[0])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return f(x)
+	})
+}
+
+// number2 is the function of two numbers.
+func number2(name, p1, p2 string, f func(a, b internal.Literal) (internal.Literal, error)) internal.Literal {
+	return internal.NewLiteralNativeFunction(name, []string{p1, p2}, func(args ...internal.Literal) (internal.Literal, error) {
+		a, err := number(name, p1, args[0])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+		b, err := number(name, p2, args[1])
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return f(a, b)
+	})
+}
+
+func float1(name string, f func(float64) float64) internal.Literal {
+	return number1(name, func(x internal.Literal) (internal.Literal, error) {
+		return internal.NewLiteralFloat(f(x.AsFloat())), nil
+	})
+}
+
+func float2(name, p1, p2 string, f func(float64, float64) float64) internal.Literal {
+	return number2(name, p1, p2, func(a, b internal.Literal) (internal.Literal, error) {
+		return internal.NewLiteralFloat(f(a.AsFloat(), b.AsFloat())), nil
+	})
+}
+
+// rounding returns integers as is and rounds floats by f.
+func rounding(name string, f func(float64) float64) internal.Literal {
+	return number1(name, func(x internal.Literal) (internal.Literal, error) {
+		if x.IsInteger() {
+			return x, nil
+		}
+
+		return internal.NewLiteralFloat(f(x.AsFloat())), nil
+	})
+}
+
+// choose returns b if it is ordered before a by the comparison operator and a otherwise; NaN wins over any number.
+func choose(a, b internal.Literal, operator kind.TokenType) (internal.Literal, error) {
+	if a.IsFloat() && math.IsNaN(a.AsFloat()) {
+		return a, nil
+	}
+	if b.IsFloat() && math.IsNaN(b.AsFloat()) {
+		return b, nil
+	}
+
+	before, err := interpreter.Binary(operator, b, a)
+	if err != nil {
+		return internal.LiteralNil, err
+	}
+	if before.AsBool() {
+		return b, nil
+	}
+
+	return a, nil
+}
+
+// toInt truncates floats toward zero and parses strings; integers which do not fit into int64 become bignums.
+func toInt(args ...internal.Literal) (internal.Literal, error) {
+	x := args[0]
+	switch {
+	case x.IsInteger():
+		return x, nil
+	case x.IsFloat():
+		f := x.AsFloat()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return internal.LiteralNil, fmt.Errorf("cannot convert %s to int", x)
+		}
+
+		i, _ := big.NewFloat(math.Trunc(f)).Int(nil)
+		return internal.NewLiteralBigInt(i), nil
+	case x.IsString():
+		i, ok := new(big.Int).SetString(strings.TrimSpace(x.AsString()), 10)
+		if !ok {
+			return internal.LiteralNil, fmt.Errorf("invalid int %q", x.AsString())
+		}
+		if i.BitLen() > interpreter.MaxIntBits {
+			return internal.LiteralNil, interpreter.ErrIntegerTooLarge
+		}
+
+		return internal.NewLiteralBigInt(i), nil
+	}
+
+	return internal.LiteralNil, fmt.Errorf("argument x of int must be a number or a string, got %s", x.TypeName())
+}
+
+func toFloat(args ...internal.Literal) (internal.Literal, error) {
+	x := args[0]
+	switch {
+	case x.IsNumber():
+		return internal.NewLiteralFloat(x.AsFloat()), nil
+	case x.IsString():
+		f, err := strconv.ParseFloat(strings.TrimSpace(x.AsString()), 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return internal.LiteralNil, fmt.Errorf("invalid float %q", x.AsString())
+		}
+
+		return internal.NewLiteralFloat(f), nil
+	}
+
+	return internal.LiteralNil, fmt.Errorf("argument x of float must be a number or a string, got %s", x.TypeName())
+}
@@ -0,0 +1,117 @@
+package natives
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/nikgalushko/gan-ilox/modules"
+	"github.com/stretchr/testify/require"
+)
+
+func bigint(s string) internal.Literal {
+	b, _ := new(big.Int).SetString(s, 10)
+	return internal.NewLiteralBigInt(b)
+}
+
+func TestMath(t *testing.T) {
+	var (
+		i = internal.NewLiteralInt
+		f = internal.NewLiteralFloat
+		s = internal.NewLiteralString
+	)
+
+	tests := []struct {
+		name     string
+		args     []internal.Literal
+		expected internal.Literal
+	}{
+		{name: "sqrt", args: []internal.Literal{i(16)}, expected: f(4)},
+		{name: "pow", args: []internal.Literal{i(2), i(10)}, expected: i(1024)},
+		{name: "pow", args: []internal.Literal{i(2), i(64)}, expected: bigint("18446744073709551616")},
+		{name: "pow", args: []internal.Literal{i(2), i(-1)}, expected: f(0.5)},
+		{name: "pow", args: []internal.Literal{f(4), f(0.5)}, expected: f(2)},
+		{name: "abs", args: []internal.Literal{i(-3)}, expected: i(3)},
+		{name: "abs", args: []internal.Literal{i(math.MinInt64)}, expected: bigint("9223372036854775808")},
+		{name: "abs", args: []internal.Literal{f(-1.5)}, expected: f(1.5)},
+		{name: "floor", args: []internal.Literal{f(-1.5)}, expected: f(-2)},
+		{name: "floor", args: []internal.Literal{i(7)}, expected: i(7)},
+		{name: "ceil", args: []internal.Literal{f(1.2)}, expected: f(2)},
+		{name: "round", args: []internal.Literal{f(2.5)}, expected: f(3)},
+		{name: "min", args: []internal.Literal{i(2), f(1.5)}, expected: f(1.5)},
+		{name: "min", args: []internal.Literal{i(2), i(2)}, expected: i(2)},
+		{name: "max", args: []internal.Literal{i(2), bigint("18446744073709551616")}, expected: bigint("18446744073709551616")},
+		{name: "max", args: []internal.Literal{i(1), f(math.NaN())}, expected: f(math.NaN())},
+		{name: "sin", args: []internal.Literal{i(0)}, expected: f(0)},
+		{name: "cos", args: []internal.Literal{i(0)}, expected: f(1)},
+		{name: "atan2", args: []internal.Literal{i(0), i(1)}, expected: f(0)},
+		{name: "log", args: []internal.Literal{i(1)}, expected: f(0)},
+		{name: "log2", args: []internal.Literal{i(8)}, expected: f(3)},
+		{name: "log10", args: []internal.Literal{i(1000)}, expected: f(3)},
+		{name: "exp", args: []internal.Literal{i(0)}, expected: f(1)},
+		{name: "isNaN", args: []internal.Literal{f(math.NaN())}, expected: internal.NewLiteralBool(true)},
+		{name: "isNaN", args: []internal.Literal{i(1)}, expected: internal.NewLiteralBool(false)},
+		{name: "isInf", args: []internal.Literal{f(math.Inf(-1))}, expected: internal.NewLiteralBool(true)},
+		{name: "int", args: []internal.Literal{f(-2.7)}, expected: i(-2)},
+		{name: "int", args: []internal.Literal{f(1e20)}, expected: bigint("100000000000000000000")},
+		{name: "int", args: []internal.Literal{s(" 42 ")}, expected: i(42)},
+		{name: "float", args: []internal.Literal{i(3)}, expected: f(3)},
+		{name: "float", args: []internal.Literal{s("2.5")}, expected: f(2.5)},
+	}
+
+	m := Math()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret, err := m[tt.name].AsFunction().Call(tt.args, nil)
+			require.NoError(t, err)
+			if tt.expected.IsFloat() && math.IsNaN(tt.expected.AsFloat()) {
+				require.True(t, math.IsNaN(ret.(internal.Literal).AsFloat()))
+				return
+			}
+			require.Equal(t, tt.expected, ret)
+		})
+	}
+
+	require.Equal(t, internal.NewLiteralFloat(math.Pi), m["pi"])
+	require.Equal(t, internal.NewLiteralFloat(math.E), m["e"])
+}
+
+func TestMath_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []internal.Literal
+		err  string
+	}{
+		{name: "sqrt", args: []internal.Literal{internal.NewLiteralString("4")}, err: "argument x of sqrt must be a number, got string"},
+		{name: "pow", args: []internal.Literal{internal.NewLiteralInt(2), internal.LiteralNil}, err: "argument y of pow must be a number, got nil"},
+		{name: "int", args: []internal.Literal{internal.NewLiteralFloat(math.NaN())}, err: "cannot convert NaN to int"},
+		{name: "int", args: []internal.Literal{internal.NewLiteralString("4.5")}, err: `invalid int "4.5"`},
+		{name: "int", args: []internal.Literal{internal.NewLiteralString(strings.Repeat("9", interpreter.MaxIntBits))}, err: "integer is too large"},
+		{name: "int", args: []internal.Literal{internal.NewLiteralBool(true)}, err: "argument x of int must be a number or a string, got bool"},
+		{name: "float", args: []internal.Literal{internal.NewLiteralString("x")}, err: `invalid float "x"`},
+	}
+
+	m := Math()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m[tt.name].AsFunction().Call(tt.args, nil)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRegistry_Install(t *testing.T) {
+	l := modules.New(env.New())
+	Std().Install(l)
+
+	m, err := l.Import("", "math", nil)
+	require.NoError(t, err)
+
+	v, err := modules.Export(m, "pi")
+	require.NoError(t, err)
+	require.Equal(t, internal.NewLiteralFloat(math.Pi), v)
+}
@@ -0,0 +1,39 @@
+// Package natives contains modules of native functions which are imported by scripts.
+package natives
+
+import (
+	"fmt"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/modules"
+)
+
+// Module is a set of native functions and constants by their names.
+type Module map[string]internal.Literal
+
+// Registry is a set of native modules by names which are used in imports: `import "math" as m;`.
+type Registry map[string]Module
+
+// Std returns the registry of the standard library.
+func Std() Registry {
+	return Registry{
+		"math":    Math(),
+		"strings": Strings(),
+	}
+}
+
+// Install makes modules of the registry importable by the loader.
+func (r Registry) Install(l *modules.Loader) {
+	for name, m := range r {
+		l.Register(name, m)
+	}
+}
+
+// number checks that the argument of the function is a number.
+func number(function, param string, v internal.Literal) (internal.Literal, error) {
+	if !v.IsNumber() {
+		return internal.LiteralNil, fmt.Errorf("argument %s of %s must be a number, got %s", param, function, v.TypeName())
+	}
+
+	return v, nil
+}
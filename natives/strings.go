@@ -0,0 +1,55 @@
+package natives
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+)
+
+// Strings returns the strings module. It has methods of strings as functions which take the string
+// as the first argument, e.g. `s.upper("abc")` is `"abc".upper()`, and chr which is the inverse of ord.
+func Strings() Module {
+	ret := Module{
+		"chr": internal.NewLiteralNativeFunction("chr", []string{"code"}, chr),
+	}
+	for _, name := range interpreter.StringMethods() {
+		ret[name] = stringFunction(name)
+	}
+
+	return ret
+}
+
+// stringFunction calls the method of the string which is the first argument by the runtime of the caller,
+// so the method checks its result against the memory limit like `"ab".repeat(n)`.
+func stringFunction(name string) internal.Literal {
+	method, _ := interpreter.StringMethod(internal.NewLiteralString(""), name)
+	params := append([]string{"s"}, method.AsFunction().ArgumentsName...)
+
+	return internal.NewLiteralRuntimeFunction(name, params, func(rt internal.Runtime, args ...internal.Literal) (internal.Literal, error) {
+		if !args[0].IsString() {
+			return internal.LiteralNil, fmt.Errorf("argument s of %s must be a string, got %s", name, args[0].TypeName())
+		}
+
+		method, err := interpreter.StringMethod(args[0], name)
+		if err != nil {
+			return internal.LiteralNil, err
+		}
+
+		return method.AsFunction().CallNative(rt, args[1:])
+	})
+}
+
+// chr returns the string of one character with the code point.
+func chr(args ...internal.Literal) (internal.Literal, error) {
+	code := args[0]
+	if !code.IsInt() {
+		return internal.LiteralNil, fmt.Errorf("argument code of chr must be an int, got %s", code.TypeName())
+	}
+	if code.AsInt() < 0 || code.AsInt() > utf8.MaxRune || !utf8.ValidRune(rune(code.AsInt())) {
+		return internal.LiteralNil, fmt.Errorf("invalid code point %d", code.AsInt())
+	}
+
+	return internal.NewLiteralString(string(rune(code.AsInt()))), nil
+}
@@ -0,0 +1,52 @@
+package natives
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrings(t *testing.T) {
+	var (
+		i = internal.NewLiteralInt
+		s = internal.NewLiteralString
+	)
+
+	tests := []struct {
+		name     string
+		args     []internal.Literal
+		expected internal.Literal
+		err      string
+	}{
+		{name: "upper", args: []internal.Literal{s("abc")}, expected: s("ABC")},
+		{name: "padLeft", args: []internal.Literal{s("7"), i(3), s("0")}, expected: s("007")},
+		{name: "ord", args: []internal.Literal{s("ñ")}, expected: i(241)},
+		{name: "chr", args: []internal.Literal{i(241)}, expected: s("ñ")},
+		{name: "upper", args: []internal.Literal{i(1)}, err: "argument s of upper must be a string, got int"},
+		{name: "contains", args: []internal.Literal{s("abc"), internal.LiteralNil}, err: "argument sub of contains must be a string, got nil"},
+		{name: "chr", args: []internal.Literal{s("a")}, err: "argument code of chr must be an int, got string"},
+		{name: "chr", args: []internal.Literal{i(0xD800)}, err: "invalid code point 55296"},
+		{name: "repeat", args: []internal.Literal{s("ab"), i(50000000)}, err: "memory limit exceeded: 100000000 bytes allocated, limit is 1048576"},
+		{name: "padLeft", args: []internal.Literal{s(""), i(math.MaxInt32), s("😀")}, err: "result of padLeft is too large"},
+	}
+
+	rt := interpreter.NewRuntime(&interpreter.Budget{}, &interpreter.Memory{Limit: 1 << 20}, nil)
+	m := Strings()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := m[tt.name].AsFunction()
+			require.Len(t, f.ArgumentsName, len(tt.args))
+
+			ret, err := f.CallNative(rt, tt.args)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, ret)
+		})
+	}
+}
@@ -21,9 +21,8 @@ func (e PraseError) Error() string {
 }
 
 type Parser struct {
-	tokens         []token.Token
-	current        int
-	insideFunction bool
+	tokens  []token.Token
+	current int
 }
 
 func New(tokens []token.Token) *Parser {
@@ -56,7 +55,12 @@ func (p *Parser) Parse() ([]internal.Stmt, error) {
 func (p *Parser) declaration() (internal.Stmt, error) {
 	if p.match(kind.Var) {
 		return p.varDeclaration()
-	} else if p.match(kind.Fun) {
+	} else if p.match(kind.Import) {
+		return p.importDeclaration()
+	} else if p.match(kind.From) {
+		return p.fromDeclaration()
+	} else if p.check(kind.Fun) && p.peekNext().Type == kind.Identifier {
+		_ = p.advance()
 		return p.funDeclaration()
 	}
 
@@ -68,43 +72,105 @@ func (p *Parser) funDeclaration() (internal.Stmt, error) {
 		return nil, errors.New("expect function name")
 	}
 
-	name := p.prev().Lexeme // consume token in p.match
+	name := p.prev() // consume token in p.match
 
 	if !p.match(kind.LeftParen) {
 		return nil, errors.New("expect '(' after function name")
 	}
 
-	ret := internal.FuncStmt{Name: name}
-	defer func() { p.insideFunction = false }()
+	params, body, err := p.function()
+	if err != nil {
+		return nil, err
+	}
 
-	if !p.match(kind.RightParen) {
-		var args []string
-		expectComma := false
-		for !p.match(kind.RightParen) && !p.isAtEnd() {
-			if expectComma && !p.match(kind.Comma) {
-				return nil, errors.New("arguments must be splitted by comma")
-			}
+	return internal.FuncStmt{Position: name.Position(), Name: name.Lexeme, Parameters: params, Body: body}, nil
+}
 
-			if !p.match(kind.Identifier) {
-				return nil, errors.New("expect argument name")
-			}
-			args = append(args, p.prev().Lexeme)
-			expectComma = true
-		}
-		ret.Parameters = args
+// function parses parameters and the body of the function; '(' is already consumed.
+func (p *Parser) function() ([]string, internal.Stmt, error) {
+	params, err := p.parameters()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if !p.match(kind.LeftBrace) {
-		return nil, errors.New("expect '{' as start of function body")
+		return nil, nil, errors.New("expect '{' as start of function body")
 	}
 
-	p.insideFunction = true
 	body, err := p.blockStmt()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	return params, body, nil
+}
+
+// parameters parses names of parameters and the closing ')'.
+func (p *Parser) parameters() ([]string, error) {
+	var params []string
+	expectComma := false
+	for !p.match(kind.RightParen) && !p.isAtEnd() {
+		if expectComma && !p.match(kind.Comma) {
+			return nil, errors.New("arguments must be splitted by comma")
+		}
+
+		if !p.match(kind.Identifier) {
+			return nil, errors.New("expect argument name")
+		}
+		params = append(params, p.prev().Lexeme)
+		expectComma = true
+	}
+
+	return params, nil
+}
+
+func (p *Parser) importDeclaration() (internal.Stmt, error) {
+	ret := internal.ImportStmt{Position: p.prev().Position()}
+	if !p.match(kind.String) {
+		return nil, errors.New("expect module path after import")
+	}
+	ret.Path = p.prev().Literal.AsString()
+
+	if !p.match(kind.As) {
+		return nil, errors.New("expect 'as' after module path")
+	}
+	if !p.match(kind.Identifier) {
+		return nil, errors.New("expect module name")
+	}
+	ret.Name = p.prev().Lexeme
+
+	if !p.match(kind.Semicolon) {
+		return nil, errors.New("expect ';' after import")
+	}
+
+	return ret, nil
+}
+
+func (p *Parser) fromDeclaration() (internal.Stmt, error) {
+	ret := internal.ImportStmt{Position: p.prev().Position()}
+	if !p.match(kind.String) {
+		return nil, errors.New("expect module path after from")
+	}
+	ret.Path = p.prev().Literal.AsString()
+
+	if !p.match(kind.Import) {
+		return nil, errors.New("expect 'import' after module path")
+	}
+	for {
+		if !p.match(kind.Identifier) {
+			return nil, errors.New("expect imported name")
+		}
+		ret.Names = append(ret.Names, p.prev().Lexeme)
+
+		if !p.match(kind.Comma) {
+			break
+		}
+	}
+
+	if !p.match(kind.Semicolon) {
+		return nil, errors.New("expect ';' after import")
 	}
 
-	ret.Body = body
 	return ret, nil
 }
 
@@ -130,7 +196,7 @@ func (p *Parser) varDeclaration() (internal.Stmt, error) {
 		return nil, errors.New("expect ; after variabl declaration")
 	}
 
-	return internal.VarStmt{Name: name.Lexeme, Expression: initializer}, nil
+	return internal.VarStmt{Position: name.Position(), Name: name.Lexeme, Expression: initializer}, nil
 }
 
 func (p *Parser) statement() (internal.Stmt, error) {
@@ -142,10 +208,20 @@ func (p *Parser) statement() (internal.Stmt, error) {
 		return p.ifStmt()
 	} else if p.match(kind.For) {
 		return p.forStmt()
+	} else if p.match(kind.While) {
+		return p.whileStmt()
+	} else if p.match(kind.Break) {
+		return p.breakStmt()
+	} else if p.match(kind.Continue) {
+		return p.continueStmt()
 	} else if p.match(kind.Return) {
 		return p.returnStmt()
 	} else if p.match(kind.Class) {
 		return p.classStmt()
+	} else if p.match(kind.Throw) {
+		return p.throwStmt()
+	} else if p.match(kind.Try) {
+		return p.tryStmt()
 	}
 	return p.expressionStatement()
 }
@@ -155,7 +231,15 @@ func (p *Parser) classStmt() (internal.Stmt, error) {
 		return nil, errors.New("expect class name")
 	}
 
-	name := p.prev().Lexeme // consume token in p.match
+	name := p.prev() // consume token in p.match
+
+	var superclass internal.Expr
+	if p.match(kind.Less) {
+		if !p.match(kind.Identifier) {
+			return nil, errors.New("expect superclass name")
+		}
+		superclass = internal.Variable{Position: p.prev().Position(), Name: p.prev().Lexeme}
+	}
 
 	if !p.match(kind.LeftBrace) {
 		return nil, errors.New("expect '{' after class name")
@@ -173,15 +257,12 @@ func (p *Parser) classStmt() (internal.Stmt, error) {
 		return nil, errors.New("expect } after class block")
 	}
 
-	return internal.ClassStmt{Name: name, Methods: methods}, nil
+	return internal.ClassStmt{Position: name.Position(), Name: name.Lexeme, Superclass: superclass, Methods: methods}, nil
 }
 
 func (p *Parser) returnStmt() (internal.Stmt, error) {
-	if !p.insideFunction {
-		return nil, errors.New("return statement is not inside a function")
-	}
-	ret := internal.RreturnStmt{}
-	if !p.match(kind.Semicolon) {
+	ret := internal.RreturnStmt{Position: p.prev().Position()}
+	if !p.check(kind.Semicolon) {
 		e, err := p.expression()
 		if err != nil {
 			return nil, err
@@ -196,13 +277,79 @@ func (p *Parser) returnStmt() (internal.Stmt, error) {
 	return ret, nil
 }
 
+func (p *Parser) throwStmt() (internal.Stmt, error) {
+	ret := internal.ThrowStmt{Position: p.prev().Position()}
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	ret.Expression = e
+
+	if !p.match(kind.Semicolon) {
+		return ret, errors.New("expect ';' after throw")
+	}
+
+	return ret, nil
+}
+
+func (p *Parser) tryStmt() (internal.Stmt, error) {
+	if !p.match(kind.LeftBrace) {
+		return nil, errors.New("expect '{' after try")
+	}
+
+	body, err := p.blockStmt()
+	if err != nil {
+		return nil, err
+	}
+	ret := internal.TryStmt{Body: body}
+
+	if p.match(kind.Catch) {
+		if !p.match(kind.LeftParen) {
+			return nil, errors.New("expect '(' after catch")
+		}
+		if !p.match(kind.Identifier) {
+			return nil, errors.New("expect variable name")
+		}
+		ret.Name = p.prev().Lexeme
+		if !p.match(kind.RightParen) {
+			return nil, errors.New("expect ')' after catch variable")
+		}
+		if !p.match(kind.LeftBrace) {
+			return nil, errors.New("expect '{' before catch block")
+		}
+
+		ret.Catch, err = p.blockStmt()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.match(kind.Finally) {
+		if !p.match(kind.LeftBrace) {
+			return nil, errors.New("expect '{' after finally")
+		}
+
+		ret.Finally, err = p.blockStmt()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ret.Catch == nil && ret.Finally == nil {
+		return nil, errors.New("expect catch or finally after try block")
+	}
+
+	return ret, nil
+}
+
 func (p *Parser) forStmt() (internal.Stmt, error) {
+	pos := p.prev().Position()
 	if p.match(kind.LeftBrace) {
 		body, err := p.blockStmt()
 		if err != nil {
 			return nil, err
 		}
-		return internal.ForStmt{Body: body}, nil
+		return internal.ForStmt{Position: pos, Body: body}, nil
 	}
 
 	if !p.match(kind.LeftParen) {
@@ -231,7 +378,7 @@ func (p *Parser) forStmt() (internal.Stmt, error) {
 		}
 	}
 
-	ret := internal.ForStmt{}
+	ret := internal.ForStmt{Position: pos}
 	if initializer == nil {
 		ret.Condition = condition
 	} else {
@@ -264,6 +411,51 @@ func (p *Parser) forStmt() (internal.Stmt, error) {
 	return ret, err
 }
 
+func (p *Parser) whileStmt() (internal.Stmt, error) {
+	pos := p.prev().Position()
+	if !p.match(kind.LeftParen) {
+		return nil, errors.New("expect '(' after while")
+	}
+
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(kind.RightParen) {
+		return nil, errors.New("expect ')' after while condition")
+	}
+
+	if !p.match(kind.LeftBrace) {
+		return nil, errors.New("expect '{' before while block")
+	}
+
+	body, err := p.blockStmt()
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.WhileStmt{Position: pos, Condition: condition, Body: body}, nil
+}
+
+func (p *Parser) breakStmt() (internal.Stmt, error) {
+	ret := internal.BreakStmt{Position: p.prev().Position()}
+	if !p.match(kind.Semicolon) {
+		return nil, errors.New("expect ';' after break")
+	}
+
+	return ret, nil
+}
+
+func (p *Parser) continueStmt() (internal.Stmt, error) {
+	ret := internal.ContinueStmt{Position: p.prev().Position()}
+	if !p.match(kind.Semicolon) {
+		return nil, errors.New("expect ';' after continue")
+	}
+
+	return ret, nil
+}
+
 func (p *Parser) ifStmt() (internal.Stmt, error) {
 	if !p.match(kind.LeftParen) {
 		return nil, errors.New("expect '(' after if")
@@ -374,13 +566,19 @@ func (p *Parser) assignment() (Expr, error) {
 				return nil, err
 			}
 
-			return internal.Assignment{Name: v.Name, Expression: e}, nil
+			return internal.Assignment{Position: v.Position, Name: v.Name, Expression: e}, nil
 		case internal.GetExpr:
 			e, err := p.assignment()
 			if err != nil {
 				return nil, err
 			}
-			return internal.SetExpr{Object: v.Expression, Name: v.Name, Value: e}, nil
+			return internal.SetExpr{Position: v.Position, Object: v.Expression, Name: v.Name, Value: e}, nil
+		case internal.Index:
+			e, err := p.assignment()
+			if err != nil {
+				return nil, err
+			}
+			return internal.IndexSet{Position: v.Position, Object: v.Object, Index: v.Index, Value: e}, nil
 		default:
 			return nil, errors.New("invalid assignment target")
 		}
@@ -428,91 +626,89 @@ func (p *Parser) and() (Expr, error) {
 }
 
 func (p *Parser) equality() (Expr, error) {
-	e, err := p.comparison()
-	if err != nil {
-		return nil, err
-	}
+	return p.binary(p.comparison, kind.EqualEqual, kind.BangEqual)
+}
 
-	for p.match(kind.EqualEqual, kind.BangEqual) {
-		operator := p.prev()
-		right, err := p.comparison()
-		if err != nil {
-			return nil, err
-		}
-		e = internal.Binary{Left: e, Operator: operator.Type, Right: right}
-	}
+func (p *Parser) comparison() (Expr, error) {
+	return p.binary(p.bitwiseOr, kind.Less, kind.LessEqual, kind.Greater, kind.GreaterEqual)
+}
 
-	return e, nil
+func (p *Parser) bitwiseOr() (Expr, error) {
+	return p.binary(p.bitwiseXor, kind.BitwiseOr)
 }
 
-func (p *Parser) comparison() (Expr, error) {
-	e, err := p.term()
-	if err != nil {
-		return nil, err
-	}
+func (p *Parser) bitwiseXor() (Expr, error) {
+	return p.binary(p.bitwiseAnd, kind.BitwiseXor)
+}
 
-	for p.match(kind.Less, kind.LessEqual, kind.Greater, kind.GreaterEqual) {
-		operator := p.prev()
-		right, err := p.term()
-		if err != nil {
-			return nil, err
-		}
-		e = internal.Binary{Left: e, Operator: operator.Type, Right: right}
-	}
+func (p *Parser) bitwiseAnd() (Expr, error) {
+	return p.binary(p.shift, kind.BitwiseAnd)
+}
 
-	return e, nil
+func (p *Parser) shift() (Expr, error) {
+	return p.binary(p.term, kind.LessLess, kind.GreaterGreater)
 }
 
 func (p *Parser) term() (Expr, error) {
-	e, err := p.factor()
+	return p.binary(p.factor, kind.Plus, kind.Minus)
+}
+
+func (p *Parser) factor() (Expr, error) {
+	return p.binary(p.unary, kind.Slash, kind.Star, kind.Percent)
+}
+
+// binary parses left-associative operators; operands are parsed by next.
+func (p *Parser) binary(next func() (Expr, error), operators ...kind.TokenType) (Expr, error) {
+	e, err := next()
 	if err != nil {
 		return nil, err
 	}
 
-	for p.match(kind.Plus, kind.Minus, kind.BitwiseAnd, kind.BitwiseOr) {
+	for p.match(operators...) {
 		operator := p.prev()
-		right, err := p.factor()
+		right, err := next()
 		if err != nil {
 			return nil, err
 		}
 
-		e = internal.Binary{Left: e, Operator: operator.Type, Right: right}
+		e = internal.Binary{Position: operator.Position(), Left: e, Operator: operator.Type, Right: right}
 	}
 
 	return e, nil
 }
 
-func (p *Parser) factor() (Expr, error) {
-	e, err := p.unary()
-	if err != nil {
-		return nil, err
-	}
-
-	for p.match(kind.Slash, kind.Star, kind.BitwiseXor) {
+func (p *Parser) unary() (Expr, error) {
+	if p.match(kind.Bang, kind.Minus, kind.BitwiseNot) {
 		operator := p.prev()
 		right, err := p.unary()
 		if err != nil {
 			return nil, err
 		}
 
-		e = internal.Binary{Left: e, Operator: operator.Type, Right: right}
+		return internal.Unary{Position: operator.Position(), Operator: operator.Type, Right: right}, nil
 	}
 
-	return e, nil
+	return p.power()
 }
 
-func (p *Parser) unary() (Expr, error) {
-	if p.match(kind.Bang, kind.Minus, kind.BitwiseNot) {
+// power is right-associative and binds tighter than unary operators on the left, so -2 ** 2 is -4.
+func (p *Parser) power() (Expr, error) {
+	e, err := p.call()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(kind.StarStar) {
 		operator := p.prev()
 		right, err := p.unary()
 		if err != nil {
 			return nil, err
 		}
 
-		return internal.Unary{Operator: operator.Type, Right: right}, nil
+		e = internal.Binary{Position: operator.Position(), Left: e, Operator: operator.Type, Right: right}
 	}
 
-	return p.call()
+	return e, nil
 }
 
 func (p *Parser) call() (Expr, error) {
@@ -531,7 +727,12 @@ func (p *Parser) call() (Expr, error) {
 			if !p.match(kind.Identifier) {
 				return nil, errors.New("expect property name after '.'")
 			}
-			e = internal.GetExpr{Name: p.prev().Lexeme, Expression: e}
+			e = internal.GetExpr{Position: p.prev().Position(), Name: p.prev().Lexeme, Expression: e}
+		} else if p.match(kind.LeftBracket) {
+			e, err = p.finishIndex(e)
+			if err != nil {
+				return nil, err
+			}
 		} else {
 			break
 		}
@@ -541,6 +742,7 @@ func (p *Parser) call() (Expr, error) {
 }
 
 func (p *Parser) finishCall(callee Expr) (Expr, error) {
+	paren := p.prev()
 	var args []Expr
 	if !p.check(kind.RightParen) {
 		for {
@@ -560,13 +762,62 @@ func (p *Parser) finishCall(callee Expr) (Expr, error) {
 		return nil, errors.New("expect ')' as end of arguments")
 	}
 
-	return internal.Call{Callee: callee, Arguments: args}, nil
+	return internal.Call{Position: paren.Position(), Callee: callee, Arguments: args}, nil
+}
+
+// finishIndex parses `[index]` or `[low:high]` where both bounds of the slice are optional.
+func (p *Parser) finishIndex(object Expr) (Expr, error) {
+	bracket := p.prev()
+
+	var (
+		index Expr
+		err   error
+	)
+	if !p.check(kind.Colon) {
+		index, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.match(kind.Colon) {
+		if index == nil {
+			return nil, errors.New("expect index")
+		}
+		if !p.match(kind.RightBracket) {
+			return nil, errors.New("expect ']' after index")
+		}
+
+		return internal.Index{Position: bracket.Position(), Object: object, Index: index}, nil
+	}
+
+	ret := internal.Slice{Position: bracket.Position(), Object: object, Low: index}
+	if !p.check(kind.RightBracket) {
+		ret.High, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !p.match(kind.RightBracket) {
+		return nil, errors.New("expect ']' after slice")
+	}
+
+	return ret, nil
 }
 
 func (p *Parser) primary() (Expr, error) {
 	if p.match(kind.Number, kind.String) {
 		return internal.LiteralExpr{Value: p.prev().Literal}, nil
 	}
+	if p.match(kind.Interpolation) {
+		return p.interpolation()
+	}
+	if p.match(kind.Fun) {
+		return p.functionExpr()
+	}
+	if p.check(kind.LeftParen) && p.isArrow() {
+		return p.arrow()
+	}
 
 	if p.match(kind.True) {
 		return internal.LiteralExpr{Value: internal.NewLiteralBool(true)}, nil
@@ -578,7 +829,47 @@ func (p *Parser) primary() (Expr, error) {
 		return internal.LiteralExpr{Value: internal.LiteralNil}, nil
 	}
 	if p.match(kind.Identifier) {
-		return internal.Variable{Name: p.prev().Lexeme}, nil
+		return internal.Variable{Position: p.prev().Position(), Name: p.prev().Lexeme}, nil
+	}
+	if p.match(kind.This) {
+		return internal.This{Position: p.prev().Position()}, nil
+	}
+	if p.match(kind.Super) {
+		keyword := p.prev()
+		if !p.match(kind.Dot) {
+			return nil, errors.New("expect '.' after 'super'")
+		}
+		if !p.match(kind.Identifier) {
+			return nil, errors.New("expect superclass method name")
+		}
+		return internal.Super{Position: keyword.Position(), Method: p.prev().Lexeme}, nil
+	}
+
+	if p.match(kind.LeftBracket) {
+		position := p.prev().Position()
+		var elements []Expr
+		for !p.check(kind.RightBracket) && !p.isAtEnd() {
+			e, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, e)
+
+			if !p.match(kind.Comma) {
+				break
+			}
+		}
+
+		if !p.match(kind.RightBracket) {
+			return nil, errors.New("expect ']' after list elements")
+		}
+
+		return internal.ListExpr{Position: position, Elements: elements}, nil
+	}
+
+	// a brace starts a block in a statement position so here it can be only a map
+	if p.match(kind.LeftBrace) {
+		return p.mapLiteral()
 	}
 
 	if p.match(kind.LeftParen) {
@@ -597,6 +888,38 @@ func (p *Parser) primary() (Expr, error) {
 	return nil, errors.New("expect expression")
 }
 
+func (p *Parser) mapLiteral() (Expr, error) {
+	ret := internal.MapExpr{Position: p.prev().Position()}
+	for !p.check(kind.RightBrace) && !p.isAtEnd() {
+		key, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.match(kind.Colon) {
+			return nil, errors.New("expect ':' after map key")
+		}
+
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Keys = append(ret.Keys, key)
+		ret.Values = append(ret.Values, value)
+
+		if !p.match(kind.Comma) {
+			break
+		}
+	}
+
+	if !p.match(kind.RightBrace) {
+		return nil, errors.New("expect '}' after map entries")
+	}
+
+	return ret, nil
+}
+
 func (p *Parser) prev() token.Token {
 	return p.tokens[p.current-1]
 }
@@ -624,6 +947,14 @@ func (p *Parser) peek() token.Token {
 	return p.tokens[p.current]
 }
 
+func (p *Parser) peekNext() token.Token {
+	if p.isAtEnd() {
+		return p.peek()
+	}
+
+	return p.tokens[p.current+1]
+}
+
 func (p *Parser) advance() token.Token {
 	if p.isAtEnd() {
 		return p.prev()
@@ -648,10 +979,82 @@ func (p *Parser) synchronize() {
 		}
 
 		switch t.Type {
-		case kind.Var, kind.For, kind.While, kind.If, kind.Else, kind.Return, kind.Print, kind.Fun, kind.Class:
+		case kind.Var, kind.For, kind.While, kind.Break, kind.Continue, kind.If, kind.Else, kind.Return, kind.Print, kind.Fun, kind.Class, kind.Throw, kind.Try, kind.Import, kind.From:
 			return
 		}
 
 		t = p.advance()
 	}
 }
+
+// interpolation parses the string with embedded expressions; its first part is already consumed.
+func (p *Parser) interpolation() (Expr, error) {
+	ret := internal.Interpolation{Position: p.prev().Position()}
+	for {
+		ret.Parts = append(ret.Parts, internal.LiteralExpr{Value: p.prev().Literal})
+
+		e, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		ret.Parts = append(ret.Parts, e)
+
+		if p.match(kind.String) {
+			ret.Parts = append(ret.Parts, internal.LiteralExpr{Value: p.prev().Literal})
+			return ret, nil
+		}
+		if !p.match(kind.Interpolation) {
+			return nil, errors.New("expect '}' after expression in string")
+		}
+	}
+}
+
+// functionExpr parses the anonymous function `fun (params) { body }`; "fun" is already consumed.
+func (p *Parser) functionExpr() (Expr, error) {
+	fun := p.prev()
+	if !p.match(kind.LeftParen) {
+		return nil, errors.New("expect '(' after fun")
+	}
+
+	params, body, err := p.function()
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.FunctionExpr{Position: fun.Position(), Parameters: params, Body: body}, nil
+}
+
+// isArrow reports whether parameters of the arrow function `(params) => expression` follow.
+func (p *Parser) isArrow() bool {
+	i := p.current + 1
+	for p.tokens[i].Type == kind.Identifier {
+		i++
+		if p.tokens[i].Type != kind.Comma {
+			break
+		}
+		i++
+	}
+
+	return p.tokens[i].Type == kind.RightParen && p.tokens[i+1].Type == kind.Arrow
+}
+
+// arrow parses `(params) => expression` which returns the value of the expression.
+func (p *Parser) arrow() (Expr, error) {
+	paren := p.advance()
+	params, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+	arrow := p.advance()
+
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.FunctionExpr{
+		Position:   paren.Position(),
+		Parameters: params,
+		Body:       internal.BlockStmt{Stmts: []internal.Stmt{internal.RreturnStmt{Position: arrow.Position(), Expression: e}}},
+	}, nil
+}
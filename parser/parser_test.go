@@ -25,11 +25,13 @@ func TestParser_Skip(t *testing.T) {
 	require.Equal(t, "expect '(' after for", err.(PraseError)[1].Error())
 	require.Equal(t, []internal.Stmt{
 		internal.VarStmt{
+			Position:   pos(2, 6),
 			Name:       "a",
 			Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 		},
 		internal.StmtExpression{
 			Expression: internal.Assignment{
+				Position:   pos(5, 2),
 				Name:       "a",
 				Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(2)},
 			},
@@ -54,6 +56,10 @@ func TestParser_HappyPath(t *testing.T) {
 	}
 }
 
+func pos(line, column int) internal.Position {
+	return internal.Position{Line: line, Column: column}
+}
+
 type Case struct {
 	Name         string
 	Code         string
@@ -68,6 +74,7 @@ var (
 		functionDeclaration,
 		returnStatement,
 		reqturnStatement2,
+		emptyReturnStatement,
 		ifStatement,
 		forStatement,
 		forStatement2,
@@ -78,6 +85,23 @@ var (
 		equality,
 		unary,
 		functionCall,
+		this,
+		subclass,
+		whileStatement,
+		list,
+		slice,
+		indexAssignment,
+		missingIndex,
+		mapLiteral,
+		interpolation,
+		functionExpr,
+		arrow,
+		badArrow,
+		tryStatement,
+		tryWithoutHandlers,
+		importAs,
+		fromImport,
+		importWithoutName,
 	}
 	classDeclaration = Case{
 		Name: "class declaration",
@@ -98,9 +122,11 @@ var (
 		`,
 		ExpectedStmt: []internal.Stmt{
 			internal.ClassStmt{
-				Name: "Foo",
+				Position: pos(2, 10),
+				Name:     "Foo",
 				Methods: []internal.FuncStmt{
 					{
+						Position:   pos(3, 5),
 						Name:       "method0",
 						Parameters: nil,
 						Body: internal.BlockStmt{
@@ -114,26 +140,29 @@ var (
 						},
 					},
 					{
+						Position:   pos(7, 5),
 						Name:       "method1",
 						Parameters: []string{"a"},
 						Body: internal.BlockStmt{
 							Stmts: []internal.Stmt{
 								internal.PrintStmt{
-									Expression: internal.Variable{Name: "a"},
+									Expression: internal.Variable{Position: pos(8, 12), Name: "a"},
 								},
 							},
 						},
 					},
 					{
+						Position:   pos(11, 5),
 						Name:       "method2",
 						Parameters: []string{"a", "b"},
 						Body: internal.BlockStmt{
 							Stmts: []internal.Stmt{
 								internal.PrintStmt{
 									Expression: internal.Binary{
-										Left:     internal.Variable{Name: "a"},
+										Position: pos(12, 14),
+										Left:     internal.Variable{Position: pos(12, 12), Name: "a"},
 										Operator: kind.Plus,
-										Right:    internal.Variable{Name: "b"},
+										Right:    internal.Variable{Position: pos(12, 16), Name: "b"},
 									},
 								},
 							},
@@ -150,13 +179,15 @@ var (
 		var a = 5-e;
 		`,
 		ExpectedStmt: []internal.Stmt{
-			internal.VarStmt{Name: "b"},
+			internal.VarStmt{Position: pos(2, 7), Name: "b"},
 			internal.VarStmt{
-				Name: "a",
+				Position: pos(3, 7),
+				Name:     "a",
 				Expression: internal.Binary{
+					Position: pos(3, 12),
 					Left:     internal.LiteralExpr{Value: internal.NewLiteralInt(5)},
 					Operator: kind.Minus,
-					Right:    internal.Variable{Name: "e"},
+					Right:    internal.Variable{Position: pos(3, 13), Name: "e"},
 				},
 			},
 		},
@@ -170,15 +201,18 @@ var (
 		`,
 		ExpectedStmt: []internal.Stmt{
 			internal.FuncStmt{
-				Name: "foo",
-				Body: internal.BlockStmt{},
+				Position: pos(2, 7),
+				Name:     "foo",
+				Body:     internal.BlockStmt{},
 			},
 			internal.FuncStmt{
+				Position:   pos(3, 7),
 				Name:       "bar",
 				Parameters: []string{"a"},
 				Body:       internal.BlockStmt{},
 			},
 			internal.FuncStmt{
+				Position:   pos(4, 7),
 				Name:       "baz",
 				Parameters: []string{"a", "b", "c", "d"},
 				Body:       internal.BlockStmt{},
@@ -188,17 +222,26 @@ var (
 	returnStatement = Case{
 		Name: "return statement outsied a function",
 		Code: `return 1;`,
-		Err:  true,
+		ExpectedStmt: []internal.Stmt{
+			internal.RreturnStmt{
+				Position: pos(1, 1),
+				Expression: internal.LiteralExpr{
+					Value: internal.NewLiteralInt(1),
+				},
+			},
+		},
 	}
 	reqturnStatement2 = Case{
 		Name: "return statement insede a function",
 		Code: `fun foo() { return 1; }`,
 		ExpectedStmt: []internal.Stmt{
 			internal.FuncStmt{
-				Name: "foo",
+				Position: pos(1, 5),
+				Name:     "foo",
 				Body: internal.BlockStmt{
 					Stmts: []internal.Stmt{
 						internal.RreturnStmt{
+							Position: pos(1, 13),
 							Expression: internal.LiteralExpr{
 								Value: internal.NewLiteralInt(1),
 							},
@@ -208,30 +251,45 @@ var (
 			},
 		},
 	}
+	emptyReturnStatement = Case{
+		Name: "return statement without value",
+		Code: `fun foo() { return; }`,
+		ExpectedStmt: []internal.Stmt{
+			internal.FuncStmt{
+				Position: pos(1, 5),
+				Name:     "foo",
+				Body: internal.BlockStmt{
+					Stmts: []internal.Stmt{internal.RreturnStmt{Position: pos(1, 13)}},
+				},
+			},
+		},
+	}
 	ifStatement = Case{
 		Name: "if else if else",
 		Code: `if (1==1) {a;} else if (1>1) {b;} else {c;}`,
 		ExpectedStmt: []internal.Stmt{
 			internal.IfStmt{
 				Condition: internal.Binary{
+					Position: pos(1, 6),
 					Left:     internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 					Operator: kind.EqualEqual,
 					Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 				},
 				If: internal.BlockStmt{
-					Stmts: []internal.Stmt{internal.StmtExpression{Expression: internal.Variable{Name: "a"}}},
+					Stmts: []internal.Stmt{internal.StmtExpression{Expression: internal.Variable{Position: pos(1, 12), Name: "a"}}},
 				},
 				Else: internal.IfStmt{
 					Condition: internal.Binary{
+						Position: pos(1, 26),
 						Left:     internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 						Operator: kind.Greater,
 						Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 					},
 					If: internal.BlockStmt{
-						Stmts: []internal.Stmt{internal.StmtExpression{Expression: internal.Variable{Name: "b"}}},
+						Stmts: []internal.Stmt{internal.StmtExpression{Expression: internal.Variable{Position: pos(1, 31), Name: "b"}}},
 					},
 					Else: internal.BlockStmt{
-						Stmts: []internal.Stmt{internal.StmtExpression{Expression: internal.Variable{Name: "c"}}},
+						Stmts: []internal.Stmt{internal.StmtExpression{Expression: internal.Variable{Position: pos(1, 41), Name: "c"}}},
 					},
 				},
 			},
@@ -242,16 +300,20 @@ var (
 		Code: `for (var a = 1; a < 10; a = a + 1) { print a; }`,
 		ExpectedStmt: []internal.Stmt{
 			internal.ForStmt{
-				Initializer: internal.VarStmt{Name: "a", Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(1)}},
+				Position:    pos(1, 1),
+				Initializer: internal.VarStmt{Position: pos(1, 10), Name: "a", Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(1)}},
 				Condition: internal.Binary{
-					Left:     internal.Variable{Name: "a"},
+					Position: pos(1, 19),
+					Left:     internal.Variable{Position: pos(1, 17), Name: "a"},
 					Operator: kind.Less,
 					Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(10)},
 				},
 				Step: internal.Assignment{
-					Name: "a",
+					Position: pos(1, 25),
+					Name:     "a",
 					Expression: internal.Binary{
-						Left:     internal.Variable{Name: "a"},
+						Position: pos(1, 31),
+						Left:     internal.Variable{Position: pos(1, 29), Name: "a"},
 						Operator: kind.Plus,
 						Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 					},
@@ -259,7 +321,7 @@ var (
 				Body: internal.BlockStmt{
 					Stmts: []internal.Stmt{
 						internal.PrintStmt{
-							Expression: internal.Variable{Name: "a"},
+							Expression: internal.Variable{Position: pos(1, 44), Name: "a"},
 						},
 					},
 				},
@@ -271,8 +333,10 @@ var (
 		Code: `for (i < 10) {}`,
 		ExpectedStmt: []internal.Stmt{
 			internal.ForStmt{
+				Position: pos(1, 1),
 				Condition: internal.Binary{
-					Left:     internal.Variable{Name: "i"},
+					Position: pos(1, 8),
+					Left:     internal.Variable{Position: pos(1, 6), Name: "i"},
 					Operator: kind.Less,
 					Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(10)},
 				},
@@ -285,7 +349,8 @@ var (
 		Code: `for {}`,
 		ExpectedStmt: []internal.Stmt{
 			internal.ForStmt{
-				Body: internal.BlockStmt{},
+				Position: pos(1, 1),
+				Body:     internal.BlockStmt{},
 			},
 		},
 	}
@@ -294,7 +359,7 @@ var (
 		Code: `i = 5;`,
 		ExpectedStmt: []internal.Stmt{
 			internal.StmtExpression{
-				Expression: internal.Assignment{Name: "i", Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(5)}},
+				Expression: internal.Assignment{Position: pos(1, 1), Name: "i", Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(5)}},
 			},
 		},
 	}
@@ -304,9 +369,10 @@ var (
 		ExpectedStmt: []internal.Stmt{
 			internal.StmtExpression{
 				Expression: internal.SetExpr{
-					Name:   "kek",
-					Object: internal.Variable{Name: "foo"},
-					Value:  internal.LiteralExpr{Value: internal.NewLiteralInt(5)},
+					Position: pos(1, 5),
+					Name:     "kek",
+					Object:   internal.Variable{Position: pos(1, 1), Name: "foo"},
+					Value:    internal.LiteralExpr{Value: internal.NewLiteralInt(5)},
 				},
 			},
 		},
@@ -342,13 +408,15 @@ var (
 		ExpectedStmt: []internal.Stmt{
 			internal.StmtExpression{
 				Expression: internal.Binary{
+					Position: pos(1, 8),
 					Left: internal.Binary{
-						Left:     internal.Variable{Name: "a"},
+						Position: pos(1, 3),
+						Left:     internal.Variable{Position: pos(1, 1), Name: "a"},
 						Operator: kind.EqualEqual,
-						Right:    internal.Variable{Name: "b"},
+						Right:    internal.Variable{Position: pos(1, 6), Name: "b"},
 					},
 					Operator: kind.BangEqual,
-					Right:    internal.Variable{Name: "c"},
+					Right:    internal.Variable{Position: pos(1, 11), Name: "c"},
 				},
 			},
 		},
@@ -359,18 +427,21 @@ var (
 		ExpectedStmt: []internal.Stmt{
 			internal.StmtExpression{
 				Expression: internal.Unary{
+					Position: pos(1, 1),
 					Operator: kind.Bang,
-					Right:    internal.Variable{Name: "a"},
+					Right:    internal.Variable{Position: pos(1, 2), Name: "a"},
 				},
 			},
 			internal.StmtExpression{
 				Expression: internal.Unary{
+					Position: pos(1, 4),
 					Operator: kind.Minus,
 					Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 				},
 			},
 			internal.StmtExpression{
 				Expression: internal.Unary{
+					Position: pos(1, 7),
 					Operator: kind.BitwiseNot,
 					Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
 				},
@@ -383,10 +454,243 @@ var (
 		ExpectedStmt: []internal.Stmt{
 			internal.StmtExpression{
 				Expression: internal.Call{
-					Arguments: []internal.Expr{internal.Variable{Name: "a"}},
-					Callee:    internal.Variable{Name: "foo"},
+					Position:  pos(1, 4),
+					Arguments: []internal.Expr{internal.Variable{Position: pos(1, 5), Name: "a"}},
+					Callee:    internal.Variable{Position: pos(1, 1), Name: "foo"},
+				},
+			},
+		},
+	}
+	this = Case{
+		Name: "this",
+		Code: "this.a = this.b;",
+		ExpectedStmt: []internal.Stmt{
+			internal.StmtExpression{
+				Expression: internal.SetExpr{
+					Position: pos(1, 6),
+					Name:     "a",
+					Object:   internal.This{Position: pos(1, 1)},
+					Value:    internal.GetExpr{Position: pos(1, 15), Name: "b", Expression: internal.This{Position: pos(1, 10)}},
+				},
+			},
+		},
+	}
+	subclass = Case{
+		Name: "subclass",
+		Code: `class B < A { m() { return super.m; } }`,
+		ExpectedStmt: []internal.Stmt{
+			internal.ClassStmt{
+				Position:   pos(1, 7),
+				Name:       "B",
+				Superclass: internal.Variable{Position: pos(1, 11), Name: "A"},
+				Methods: []internal.FuncStmt{
+					{
+						Position: pos(1, 15),
+						Name:     "m",
+						Body: internal.BlockStmt{
+							Stmts: []internal.Stmt{
+								internal.RreturnStmt{Position: pos(1, 21), Expression: internal.Super{Position: pos(1, 28), Method: "m"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	whileStatement = Case{
+		Name: "while with break and continue",
+		Code: `while (a) { break; continue; }`,
+		ExpectedStmt: []internal.Stmt{
+			internal.WhileStmt{
+				Position:  pos(1, 1),
+				Condition: internal.Variable{Position: pos(1, 8), Name: "a"},
+				Body: internal.BlockStmt{
+					Stmts: []internal.Stmt{internal.BreakStmt{Position: pos(1, 13)}, internal.ContinueStmt{Position: pos(1, 20)}},
+				},
+			},
+		},
+	}
+	list = Case{
+		Name: "list literal and index",
+		Code: `[1, [2],][0];`,
+		ExpectedStmt: []internal.Stmt{
+			internal.StmtExpression{
+				Expression: internal.Index{
+					Position: pos(1, 10),
+					Object: internal.ListExpr{
+						Position: pos(1, 1),
+						Elements: []internal.Expr{
+							internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
+							internal.ListExpr{
+								Position: pos(1, 5),
+								Elements: []internal.Expr{internal.LiteralExpr{Value: internal.NewLiteralInt(2)}},
+							},
+						},
+					},
+					Index: internal.LiteralExpr{Value: internal.NewLiteralInt(0)},
+				},
+			},
+		},
+	}
+	slice = Case{
+		Name: "slices with omitted bounds",
+		Code: `xs[1:]; xs[:-1];`,
+		ExpectedStmt: []internal.Stmt{
+			internal.StmtExpression{
+				Expression: internal.Slice{
+					Position: pos(1, 3),
+					Object:   internal.Variable{Position: pos(1, 1), Name: "xs"},
+					Low:      internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
+				},
+			},
+			internal.StmtExpression{
+				Expression: internal.Slice{
+					Position: pos(1, 11),
+					Object:   internal.Variable{Position: pos(1, 9), Name: "xs"},
+					High: internal.Unary{
+						Position: pos(1, 13),
+						Operator: kind.Minus,
+						Right:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
+					},
+				},
+			},
+		},
+	}
+	indexAssignment = Case{
+		Name: "index assignment",
+		Code: `xs[0] = 1;`,
+		ExpectedStmt: []internal.Stmt{
+			internal.StmtExpression{
+				Expression: internal.IndexSet{
+					Position: pos(1, 3),
+					Object:   internal.Variable{Position: pos(1, 1), Name: "xs"},
+					Index:    internal.LiteralExpr{Value: internal.NewLiteralInt(0)},
+					Value:    internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
+				},
+			},
+		},
+	}
+	missingIndex = Case{
+		Name: "missing index",
+		Code: `xs[];`,
+		Err:  true,
+	}
+	mapLiteral = Case{
+		Name: "map literal",
+		Code: `print {"a": 1, b: {}};`,
+		ExpectedStmt: []internal.Stmt{
+			internal.PrintStmt{
+				Expression: internal.MapExpr{
+					Position: pos(1, 7),
+					Keys: []internal.Expr{
+						internal.LiteralExpr{Value: internal.NewLiteralString("a")},
+						internal.Variable{Position: pos(1, 16), Name: "b"},
+					},
+					Values: []internal.Expr{
+						internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
+						internal.MapExpr{Position: pos(1, 19)},
+					},
+				},
+			},
+		},
+	}
+	interpolation = Case{
+		Name: "interpolation",
+		Code: `print "a ${b} c ${"d"}";`,
+		ExpectedStmt: []internal.Stmt{
+			internal.PrintStmt{
+				Expression: internal.Interpolation{
+					Position: pos(1, 7),
+					Parts: []internal.Expr{
+						internal.LiteralExpr{Value: internal.NewLiteralString("a ")},
+						internal.Variable{Position: pos(1, 12), Name: "b"},
+						internal.LiteralExpr{Value: internal.NewLiteralString(" c ")},
+						internal.LiteralExpr{Value: internal.NewLiteralString("d")},
+						internal.LiteralExpr{Value: internal.NewLiteralString("")},
+					},
+				},
+			},
+		},
+	}
+	functionExpr = Case{
+		Name: "function expression",
+		Code: `fun (a) { return a; }(1);`,
+		ExpectedStmt: []internal.Stmt{
+			internal.StmtExpression{
+				Expression: internal.Call{
+					Position: pos(1, 22),
+					Callee: internal.FunctionExpr{
+						Position:   pos(1, 1),
+						Parameters: []string{"a"},
+						Body: internal.BlockStmt{Stmts: []internal.Stmt{
+							internal.RreturnStmt{Position: pos(1, 11), Expression: internal.Variable{Position: pos(1, 18), Name: "a"}},
+						}},
+					},
+					Arguments: []internal.Expr{internal.LiteralExpr{Value: internal.NewLiteralInt(1)}},
+				},
+			},
+		},
+	}
+	arrow = Case{
+		Name: "arrow",
+		Code: `var f = (a, b) => a;`,
+		ExpectedStmt: []internal.Stmt{
+			internal.VarStmt{
+				Position: pos(1, 5),
+				Name:     "f",
+				Expression: internal.FunctionExpr{
+					Position:   pos(1, 9),
+					Parameters: []string{"a", "b"},
+					Body: internal.BlockStmt{Stmts: []internal.Stmt{
+						internal.RreturnStmt{Position: pos(1, 16), Expression: internal.Variable{Position: pos(1, 19), Name: "a"}},
+					}},
 				},
 			},
 		},
 	}
+	badArrow = Case{
+		Name: "arrow without expression",
+		Code: `var f = (a, b) => ;`,
+		Err:  true,
+	}
+	tryStatement = Case{
+		Name: "try statement",
+		Code: `try { throw e; } catch (e) { print e; } finally {}`,
+		ExpectedStmt: []internal.Stmt{
+			internal.TryStmt{
+				Body: internal.BlockStmt{Stmts: []internal.Stmt{
+					internal.ThrowStmt{Position: pos(1, 7), Expression: internal.Variable{Position: pos(1, 13), Name: "e"}},
+				}},
+				Name: "e",
+				Catch: internal.BlockStmt{Stmts: []internal.Stmt{
+					internal.PrintStmt{Expression: internal.Variable{Position: pos(1, 36), Name: "e"}},
+				}},
+				Finally: internal.BlockStmt{},
+			},
+		},
+	}
+	tryWithoutHandlers = Case{
+		Name: "try without catch and finally",
+		Code: `try {}`,
+		Err:  true,
+	}
+	importAs = Case{
+		Name: "import as",
+		Code: `import "lib/strings.lox" as s;`,
+		ExpectedStmt: []internal.Stmt{
+			internal.ImportStmt{Position: pos(1, 1), Path: "lib/strings.lox", Name: "s"},
+		},
+	}
+	fromImport = Case{
+		Name: "from import",
+		Code: `from "x.lox" import a, b;`,
+		ExpectedStmt: []internal.Stmt{
+			internal.ImportStmt{Position: pos(1, 1), Path: "x.lox", Names: []string{"a", "b"}},
+		},
+	}
+	importWithoutName = Case{
+		Name: "import without name",
+		Code: `import "x.lox";`,
+		Err:  true,
+	}
 )
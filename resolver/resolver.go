@@ -0,0 +1,477 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+)
+
+type ResolveError []error
+
+func (e ResolveError) Error() string {
+	var arr []string
+	for _, err := range e {
+		arr = append(arr, err.Error())
+	}
+
+	return strings.Join(arr, "\n")
+}
+
+type functionType int8
+
+const (
+	functionNone functionType = iota
+	functionFunction
+	functionMethod
+	functionInitializer
+)
+
+type classType int8
+
+const (
+	classNone classType = iota
+	classClass
+	classSubclass
+)
+
+// Resolver binds every variable to the scope where it is declared.
+// Scopes of the resolver mirror environments which are created by interpreter:
+// each block, each for-loop with initializer and parameters of each function.
+type Resolver struct {
+	// scopes is a stack of local scopes; value is true when variable is defined
+	// and false when it is only declared.
+	scopes          []map[string]bool
+	currentFunction functionType
+	currentClass    classType
+	// loops is a number of loops which enclose the current statement inside the current function.
+	loops int
+	errs  ResolveError
+}
+
+func New() *Resolver {
+	return &Resolver{}
+}
+
+// Resolve returns a copy of stmts where each variable knows its scope depth.
+func (r *Resolver) Resolve(stmts []internal.Stmt) ([]internal.Stmt, error) {
+	ret := r.stmts(stmts)
+	if len(r.errs) == 0 {
+		return ret, nil
+	}
+
+	return ret, r.errs
+}
+
+func (r *Resolver) VisitStmtExpression(s internal.StmtExpression) any {
+	s.Expression = r.expr(s.Expression)
+	return s
+}
+
+func (r *Resolver) VisitPrintStmt(s internal.PrintStmt) any {
+	s.Expression = r.expr(s.Expression)
+	return s
+}
+
+func (r *Resolver) VisitVarStmt(s internal.VarStmt) any {
+	r.declare(s.Name, s.Position)
+	s.Expression = r.expr(s.Expression)
+	r.define(s.Name)
+
+	return s
+}
+
+func (r *Resolver) VisitBlockStmt(s internal.BlockStmt) any {
+	r.beginScope()
+	defer r.endScope()
+
+	s.Stmts = r.stmts(s.Stmts)
+	return s
+}
+
+func (r *Resolver) VisitIfStmt(s internal.IfStmt) any {
+	s.Condition = r.expr(s.Condition)
+	s.If = r.stmt(s.If)
+	s.Else = r.stmt(s.Else)
+
+	return s
+}
+
+func (r *Resolver) VisitElseStmt(s internal.ElseStmt) any {
+	s.If = r.stmt(s.If)
+	s.Block = r.stmt(s.Block)
+
+	return s
+}
+
+func (r *Resolver) VisitForSmt(s internal.ForStmt) any {
+	if s.Initializer != nil {
+		r.beginScope()
+		defer r.endScope()
+	}
+
+	s.Initializer = r.stmt(s.Initializer)
+	s.Condition = r.expr(s.Condition)
+	s.Step = r.expr(s.Step)
+
+	r.loops++
+	defer func() { r.loops-- }()
+	s.Body = r.stmt(s.Body)
+
+	return s
+}
+
+func (r *Resolver) VisitWhileStmt(s internal.WhileStmt) any {
+	r.loops++
+	defer func() { r.loops-- }()
+
+	s.Condition = r.expr(s.Condition)
+	s.Body = r.stmt(s.Body)
+
+	return s
+}
+
+func (r *Resolver) VisitBreakStmt(s internal.BreakStmt) any {
+	if r.loops == 0 {
+		r.error(s.Position, "break", "break statement is not inside a loop")
+	}
+
+	return s
+}
+
+func (r *Resolver) VisitContinueStmt(s internal.ContinueStmt) any {
+	if r.loops == 0 {
+		r.error(s.Position, "continue", "continue statement is not inside a loop")
+	}
+
+	return s
+}
+
+func (r *Resolver) VisitThrowStmt(s internal.ThrowStmt) any {
+	s.Expression = r.expr(s.Expression)
+
+	return s
+}
+
+func (r *Resolver) VisitTryStmt(s internal.TryStmt) any {
+	s.Body = r.stmt(s.Body)
+
+	if s.Catch != nil {
+		// the scope of the catch clause is new, so its variable cannot be redeclared
+		r.beginScope()
+		r.define(s.Name)
+		s.Catch = r.stmt(s.Catch)
+		r.endScope()
+	}
+
+	s.Finally = r.stmt(s.Finally)
+
+	return s
+}
+
+func (r *Resolver) VisitImportStmt(s internal.ImportStmt) any {
+	if len(r.scopes) != 0 {
+		r.error(s.Position, "import", "import statement is not at top level")
+	}
+
+	if s.Name != "" {
+		r.declare(s.Name, s.Position)
+		r.define(s.Name)
+	}
+	for _, name := range s.Names {
+		r.declare(name, s.Position)
+		r.define(name)
+	}
+
+	return s
+}
+
+func (r *Resolver) VisitFuncStmt(s internal.FuncStmt) any {
+	r.declare(s.Name, s.Position)
+	r.define(s.Name)
+
+	return r.function(s, functionFunction)
+}
+
+func (r *Resolver) VisitReturnStmt(s internal.RreturnStmt) any {
+	if r.currentFunction == functionNone {
+		r.error(s.Position, "return", "return statement is not inside a function")
+	}
+	if r.currentFunction == functionInitializer && s.Expression != nil {
+		r.error(s.Position, "return", "can't return a value from an initializer")
+	}
+
+	s.Expression = r.expr(s.Expression)
+	return s
+}
+
+func (r *Resolver) VisitClassStmt(s internal.ClassStmt) any {
+	r.declare(s.Name, s.Position)
+	r.define(s.Name)
+
+	prevClass := r.currentClass
+	r.currentClass = classClass
+	defer func() { r.currentClass = prevClass }()
+
+	if s.Superclass != nil {
+		if v, ok := s.Superclass.(internal.Variable); ok && v.Name == s.Name {
+			r.error(v.Position, v.Name, "a class can't inherit from itself")
+		}
+
+		r.currentClass = classSubclass
+		s.Superclass = r.expr(s.Superclass)
+
+		// "super" lives in its own scope around the scope of "this"
+		r.beginScope()
+		defer r.endScope()
+		r.define("super")
+	}
+
+	// methods are bound to an instance in a separate scope which holds "this"
+	r.beginScope()
+	defer r.endScope()
+	r.define("this")
+
+	methods := s.Methods
+	s.Methods = nil
+	for _, m := range methods {
+		t := functionMethod
+		if m.Name == "init" {
+			t = functionInitializer
+		}
+		s.Methods = append(s.Methods, r.function(m, t))
+	}
+
+	return s
+}
+
+func (r *Resolver) VisitBinaryExpr(e internal.Binary) any {
+	e.Left = r.expr(e.Left)
+	e.Right = r.expr(e.Right)
+
+	return e
+}
+
+func (r *Resolver) VisitGroupingExpr(e internal.Grouping) any {
+	e.Expression = r.expr(e.Expression)
+	return e
+}
+
+func (r *Resolver) VisitLiteralExpr(e internal.LiteralExpr) any {
+	return e
+}
+
+func (r *Resolver) VisitUnaryExpr(e internal.Unary) any {
+	e.Right = r.expr(e.Right)
+	return e
+}
+
+func (r *Resolver) VisitVariableExpr(e internal.Variable) any {
+	if len(r.scopes) != 0 {
+		if defined, ok := r.scopes[len(r.scopes)-1][e.Name]; ok && !defined {
+			r.error(e.Position, e.Name, "can't read local variable '"+e.Name+"' in its own initializer")
+		}
+	}
+
+	e.Depth = r.local(e.Name)
+	return e
+}
+
+func (r *Resolver) VisitAssignmentExpr(e internal.Assignment) any {
+	e.Expression = r.expr(e.Expression)
+	e.Depth = r.local(e.Name)
+
+	return e
+}
+
+func (r *Resolver) VisitLogicalExpr(e internal.Logical) any {
+	e.Left = r.expr(e.Left)
+	e.Right = r.expr(e.Right)
+
+	return e
+}
+
+func (r *Resolver) VisitCallExpr(e internal.Call) any {
+	e.Callee = r.expr(e.Callee)
+	e.Arguments = r.exprs(e.Arguments)
+
+	return e
+}
+
+func (r *Resolver) VisitGetExpr(e internal.GetExpr) any {
+	e.Expression = r.expr(e.Expression)
+	return e
+}
+
+func (r *Resolver) VisitSetExpr(e internal.SetExpr) any {
+	e.Object = r.expr(e.Object)
+	e.Value = r.expr(e.Value)
+
+	return e
+}
+
+func (r *Resolver) VisitThisExpr(e internal.This) any {
+	if r.currentClass == classNone {
+		r.error(e.Position, "this", "can't use 'this' outside of a class")
+		return e
+	}
+
+	e.Depth = r.local("this")
+	return e
+}
+
+func (r *Resolver) VisitSuperExpr(e internal.Super) any {
+	if r.currentClass == classNone {
+		r.error(e.Position, "super."+e.Method, "can't use 'super' outside of a class")
+		return e
+	}
+	if r.currentClass != classSubclass {
+		r.error(e.Position, "super."+e.Method, "can't use 'super' in a class with no superclass")
+		return e
+	}
+
+	e.Depth = r.local("super")
+	return e
+}
+
+func (r *Resolver) VisitListExpr(e internal.ListExpr) any {
+	e.Elements = r.exprs(e.Elements)
+	return e
+}
+
+func (r *Resolver) VisitMapExpr(e internal.MapExpr) any {
+	e.Keys = r.exprs(e.Keys)
+	e.Values = r.exprs(e.Values)
+
+	return e
+}
+
+func (r *Resolver) VisitIndexExpr(e internal.Index) any {
+	e.Object = r.expr(e.Object)
+	e.Index = r.expr(e.Index)
+
+	return e
+}
+
+func (r *Resolver) VisitSliceExpr(e internal.Slice) any {
+	e.Object = r.expr(e.Object)
+	e.Low = r.expr(e.Low)
+	e.High = r.expr(e.High)
+
+	return e
+}
+
+func (r *Resolver) VisitIndexSetExpr(e internal.IndexSet) any {
+	e.Object = r.expr(e.Object)
+	e.Index = r.expr(e.Index)
+	e.Value = r.expr(e.Value)
+
+	return e
+}
+
+func (r *Resolver) VisitInterpolationExpr(e internal.Interpolation) any {
+	e.Parts = r.exprs(e.Parts)
+	return e
+}
+
+func (r *Resolver) VisitFunctionExpr(e internal.FunctionExpr) any {
+	fn := r.function(internal.FuncStmt{Position: e.Position, Parameters: e.Parameters, Body: e.Body}, functionFunction)
+	e.Body = fn.Body
+
+	return e
+}
+
+func (r *Resolver) function(s internal.FuncStmt, t functionType) internal.FuncStmt {
+	prevFunction, prevLoops := r.currentFunction, r.loops
+	r.currentFunction, r.loops = t, 0
+	defer func() { r.currentFunction, r.loops = prevFunction, prevLoops }()
+
+	r.beginScope()
+	defer r.endScope()
+
+	// parameters have no positions of their own, so they are reported at the function
+	for _, p := range s.Parameters {
+		r.declare(p, s.Position)
+		r.define(p)
+	}
+
+	s.Body = r.stmt(s.Body)
+	return s
+}
+
+func (r *Resolver) stmts(stmts []internal.Stmt) []internal.Stmt {
+	var ret []internal.Stmt
+	for _, s := range stmts {
+		ret = append(ret, r.stmt(s))
+	}
+
+	return ret
+}
+
+func (r *Resolver) stmt(s internal.Stmt) internal.Stmt {
+	if s == nil {
+		return nil
+	}
+
+	return s.Accept(r).(internal.Stmt)
+}
+
+func (r *Resolver) exprs(exprs []internal.Expr) []internal.Expr {
+	var ret []internal.Expr
+	for _, e := range exprs {
+		ret = append(ret, r.expr(e))
+	}
+
+	return ret
+}
+
+func (r *Resolver) expr(e internal.Expr) internal.Expr {
+	if e == nil {
+		return nil
+	}
+
+	return e.Accept(r).(internal.Expr)
+}
+
+func (r *Resolver) local(name string) int {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name]; ok {
+			return len(r.scopes) - 1 - i
+		}
+	}
+
+	return internal.GlobalDepth
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *Resolver) declare(name string, pos internal.Position) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	scope := r.scopes[len(r.scopes)-1]
+	if _, ok := scope[name]; ok {
+		r.error(pos, name, "variable '"+name+"' is already declared in this scope")
+	}
+	scope[name] = false
+}
+
+func (r *Resolver) define(name string) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	r.scopes[len(r.scopes)-1][name] = true
+}
+
+// error records the error at pos in the format of runtime errors; near is a piece of the source code at pos.
+func (r *Resolver) error(pos internal.Position, near, msg string) {
+	r.errs = append(r.errs, fmt.Errorf("%d:%d: %s near '%s'", pos.Line, pos.Column, msg, near))
+}
@@ -0,0 +1,199 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/scanner"
+	"github.com/nikgalushko/gan-ilox/token/kind"
+	"github.com/stretchr/testify/require"
+)
+
+func resolve(t *testing.T, code string) ([]internal.Stmt, error) {
+	t.Helper()
+
+	tokens, err := scanner.NewScanner(code).ScanTokens()
+	require.NoError(t, err)
+
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+
+	return New().Resolve(stmts)
+}
+
+func pos(line, column int) internal.Position {
+	return internal.Position{Line: line, Column: column}
+}
+
+func TestResolver_Depth(t *testing.T) {
+	stmts, err := resolve(t, `
+	var a = 1;
+	{
+		var b = a;
+		fun f(c) {
+			b = c + a;
+		}
+	}
+	`)
+	require.NoError(t, err)
+	require.Equal(t, []internal.Stmt{
+		internal.VarStmt{
+			Position:   pos(2, 6),
+			Name:       "a",
+			Expression: internal.LiteralExpr{Value: internal.NewLiteralInt(1)},
+		},
+		internal.BlockStmt{
+			Stmts: []internal.Stmt{
+				internal.VarStmt{
+					Position:   pos(4, 7),
+					Name:       "b",
+					Expression: internal.Variable{Position: pos(4, 11), Name: "a", Depth: internal.GlobalDepth},
+				},
+				internal.FuncStmt{
+					Position:   pos(5, 7),
+					Name:       "f",
+					Parameters: []string{"c"},
+					Body: internal.BlockStmt{
+						Stmts: []internal.Stmt{
+							internal.StmtExpression{
+								Expression: internal.Assignment{
+									Position: pos(6, 4),
+									Name:     "b",
+									Expression: internal.Binary{
+										Position: pos(6, 10),
+										Left:     internal.Variable{Position: pos(6, 8), Name: "c", Depth: 1},
+										Operator: kind.Plus,
+										Right:    internal.Variable{Position: pos(6, 12), Name: "a", Depth: internal.GlobalDepth},
+									},
+									Depth: 2,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, stmts)
+}
+
+func TestResolver_ForLoopScope(t *testing.T) {
+	stmts, err := resolve(t, `
+	fun f() {
+		for (var i = 0; i < 1; i = i + 1) {
+			print i;
+		}
+	}
+	`)
+	require.NoError(t, err)
+
+	body := stmts[0].(internal.FuncStmt).Body.(internal.BlockStmt)
+	loop := body.Stmts[0].(internal.ForStmt)
+	require.Equal(t, internal.Variable{Position: pos(3, 19), Name: "i", Depth: 0}, loop.Condition.(internal.Binary).Left)
+	require.Equal(t, 0, loop.Step.(internal.Assignment).Depth)
+	require.Equal(t,
+		internal.PrintStmt{Expression: internal.Variable{Position: pos(4, 10), Name: "i", Depth: 1}},
+		loop.Body.(internal.BlockStmt).Stmts[0],
+	)
+}
+
+func TestResolver_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		err  string
+	}{
+		{
+			name: "return at top level",
+			code: `return 1;`,
+			err:  "1:1: return statement is not inside a function near 'return'",
+		},
+		{
+			name: "return inside a block at top level",
+			code: `{ return; }`,
+			err:  "1:3: return statement is not inside a function near 'return'",
+		},
+		{
+			name: "read local in its own initializer",
+			code: `{ var a = 1; { var a = a + 1; } }`,
+			err:  "1:24: can't read local variable 'a' in its own initializer near 'a'",
+		},
+		{
+			name: "redeclare local",
+			code: `fun f() { var a = 1; var a = 2; }`,
+			err:  "1:26: variable 'a' is already declared in this scope near 'a'",
+		},
+		{
+			name: "this at top level",
+			code: `print this;`,
+			err:  "1:7: can't use 'this' outside of a class near 'this'",
+		},
+		{
+			name: "this inside a function",
+			code: `fun f() { return this; }`,
+			err:  "1:18: can't use 'this' outside of a class near 'this'",
+		},
+		{
+			name: "return a value from an initializer",
+			code: `class A { init() { return 1; } }`,
+			err:  "1:20: can't return a value from an initializer near 'return'",
+		},
+		{
+			name: "import inside a function",
+			code: `fun f() { import "a.lox" as a; }`,
+			err:  "1:11: import statement is not at top level near 'import'",
+		},
+		{
+			name: "inherit from itself",
+			code: `class A < A {}`,
+			err:  "1:11: a class can't inherit from itself near 'A'",
+		},
+		{
+			name: "super outside of a class",
+			code: `fun f() { super.f(); }`,
+			err:  "1:11: can't use 'super' outside of a class near 'super.f'",
+		},
+		{
+			name: "super without superclass",
+			code: `class A { f() { super.f(); } }`,
+			err:  "1:17: can't use 'super' in a class with no superclass near 'super.f'",
+		},
+		{
+			name: "break outside of a loop",
+			code: `break;`,
+			err:  "1:1: break statement is not inside a loop near 'break'",
+		},
+		{
+			name: "continue inside a function inside a loop",
+			code: `while (true) { fun f() { continue; } }`,
+			err:  "1:26: continue statement is not inside a loop near 'continue'",
+		},
+		{
+			name: "redeclare parameter",
+			code: `fun f(a, a) {}`,
+			err:  "1:5: variable 'a' is already declared in this scope near 'a'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolve(t, tt.code)
+			require.Error(t, err)
+			require.Equal(t, tt.err, err.(ResolveError)[0].Error())
+		})
+	}
+}
+
+func TestResolver_Valid(t *testing.T) {
+	for _, code := range []string{
+		`var a = 1; var a = a + 1;`,
+		`fun outer() { fun inner() { return 1; } return inner; }`,
+		`fun f() { { var a = 1; } var a = 2; }`,
+		`class A { m() { fun f() { return this; } return f; } }`,
+		`class A { init() { return; } }`,
+		`for { while (true) { break; } continue; }`,
+	} {
+		_, err := resolve(t, code)
+		require.NoError(t, err, code)
+	}
+}
@@ -1,8 +1,12 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/nikgalushko/gan-ilox/internal"
@@ -11,18 +15,31 @@ import (
 )
 
 type SyntaxError struct {
-	lineNumber     int
-	message, cause string
+	lineNumber, column int
+	message, cause     string
 }
 
 func (e SyntaxError) Error() string {
-	return fmt.Sprintf("%d\t|\tError%s: %s", e.lineNumber, e.cause, e.message)
+	return fmt.Sprintf("%d:%d\t|\tError%s: %s", e.lineNumber, e.column, e.cause, e.message)
 }
 
 type Scanner struct {
 	source               []rune
 	start, current, line int
-	tokens               []token.Token
+	// lineStart is an index of the first rune of the current line
+	lineStart int
+	// startLine and startColumn are the position of the current token
+	startLine, startColumn int
+	tokens                 []token.Token
+	// interpolations are `${` of strings whose expressions are being scanned
+	interpolations []interpolation
+}
+
+// interpolation is the start of the expression embedded in the string.
+type interpolation struct {
+	line, column int
+	// depth is the number of unclosed braces of the expression
+	depth int
 }
 
 func NewScanner(source string) *Scanner {
@@ -35,13 +52,20 @@ func NewScanner(source string) *Scanner {
 func (s *Scanner) ScanTokens() ([]token.Token, error) {
 	for !s.isAtEnd() {
 		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.start - s.lineStart + 1
 		err := s.scanToken()
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	s.tokens = append(s.tokens, token.New(kind.EOF, "", s.line, internal.LiteralNil))
+	if n := len(s.interpolations); n > 0 {
+		i := s.interpolations[n-1]
+		return nil, SyntaxError{lineNumber: i.line, column: i.column, message: "Unterminated string interpolation"}
+	}
+
+	s.tokens = append(s.tokens, token.New(kind.EOF, "", s.line, s.current-s.lineStart+1, internal.LiteralNil))
 
 	return s.tokens, nil
 }
@@ -54,9 +78,29 @@ func (s *Scanner) scanToken() error {
 	case ')':
 		s.appendSingleToken(kind.RightParen)
 	case '{':
+		if n := len(s.interpolations); n > 0 {
+			s.interpolations[n-1].depth++
+		}
 		s.appendSingleToken(kind.LeftBrace)
 	case '}':
+		if n := len(s.interpolations); n > 0 {
+			if s.interpolations[n-1].depth == 0 {
+				if s.tokens[len(s.tokens)-1].Type == kind.Interpolation {
+					return SyntaxError{lineNumber: s.line, column: s.startColumn, message: "Expect expression in string interpolation"}
+				}
+				// the embedded expression is over, so the rest of the string follows
+				s.interpolations = s.interpolations[:n-1]
+				return s.string()
+			}
+			s.interpolations[n-1].depth--
+		}
 		s.appendSingleToken(kind.RightBrace)
+	case '[':
+		s.appendSingleToken(kind.LeftBracket)
+	case ']':
+		s.appendSingleToken(kind.RightBracket)
+	case ':':
+		s.appendSingleToken(kind.Colon)
 	case ',':
 		s.appendSingleToken(kind.Comma)
 	case '.':
@@ -68,7 +112,13 @@ func (s *Scanner) scanToken() error {
 	case '-':
 		s.appendSingleToken(kind.Minus)
 	case '*':
-		s.appendSingleToken(kind.Star)
+		k := kind.Star
+		if s.match('*') {
+			k = kind.StarStar
+		}
+		s.appendSingleToken(k)
+	case '%':
+		s.appendSingleToken(kind.Percent)
 	case '&':
 		s.appendSingleToken(kind.BitwiseAnd)
 	case '|':
@@ -87,18 +137,24 @@ func (s *Scanner) scanToken() error {
 		k := kind.Equal
 		if s.match('=') {
 			k = kind.EqualEqual
+		} else if s.match('>') {
+			k = kind.Arrow
 		}
 		s.appendSingleToken(k)
 	case '<':
 		k := kind.Less
 		if s.match('=') {
 			k = kind.LessEqual
+		} else if s.match('<') {
+			k = kind.LessLess
 		}
 		s.appendSingleToken(k)
 	case '>':
 		k := kind.Greater
 		if s.match('=') {
 			k = kind.GreaterEqual
+		} else if s.match('>') {
+			k = kind.GreaterGreater
 		}
 		s.appendSingleToken(k)
 	case '/':
@@ -112,7 +168,7 @@ func (s *Scanner) scanToken() error {
 			for !(prevRune == '*' && s.peek() == '/') && !s.isAtEnd() {
 				prevRune = s.advance()
 				if prevRune == '\n' {
-					s.line++
+					s.newLine()
 				}
 			}
 			_ = s.advance() // read last /
@@ -121,12 +177,22 @@ func (s *Scanner) scanToken() error {
 		}
 	case ' ', '\r', '\t':
 	case '\n':
-		s.line++
+		s.newLine()
 	case '"':
-		err := s.string()
+		var err error
+		if s.peek() == '"' && s.peekNext() == '"' {
+			s.current += 2
+			err = s.textBlock()
+		} else {
+			err = s.string()
+		}
 		if err != nil {
 			return err
 		}
+	case '`':
+		if err := s.rawString(); err != nil {
+			return err
+		}
 	default:
 		if unicode.IsDigit(r) {
 			if err := s.number(); err != nil {
@@ -137,7 +203,7 @@ func (s *Scanner) scanToken() error {
 				return err
 			}
 		} else {
-			return SyntaxError{lineNumber: s.line, message: "Unexpected character"}
+			return SyntaxError{lineNumber: s.line, column: s.startColumn, message: "Unexpected character"}
 		}
 	}
 
@@ -159,64 +225,149 @@ func (s *Scanner) identifier() error {
 	return nil
 }
 
+// number scans decimal integers and floats with an optional exponent and
+// hex, octal and binary integers; digits may be separated by '_'.
 func (s *Scanner) number() error {
-	for unicode.IsDigit(s.peek()) {
-		_ = s.advance()
+	if s.source[s.start] == '0' {
+		if base, ok := bases[unicode.ToLower(s.peek())]; ok {
+			_ = s.advance()
+			return s.integer(base)
+		}
+	}
+
+	if err := s.digits(isDecimal); err != nil {
+		return err
 	}
 
 	isFloat := false
-	if s.peek() == '.' && unicode.IsDigit(s.peekNext()) {
+	if s.peek() == '.' && isDecimal(s.peekNext()) {
 		isFloat = true
 		_ = s.advance()
 
-		for unicode.IsDigit(s.peek()) {
-			_ = s.advance()
+		if err := s.digits(isDecimal); err != nil {
+			return err
+		}
+	}
+
+	if e := s.peek(); e == 'e' || e == 'E' {
+		sign := s.peekNext() == '+' || s.peekNext() == '-'
+		exponent := s.current + 1
+		if sign {
+			exponent++
+		}
+
+		if exponent < len(s.source) && isDecimal(s.source[exponent]) {
+			isFloat = true
+			s.current = exponent
+			if err := s.digits(isDecimal); err != nil {
+				return err
+			}
 		}
 	}
 
 	text := string(s.source[s.start:s.current])
+	digits := strings.ReplaceAll(text, "_", "")
 	var l internal.Literal
 
 	if isFloat {
-		n, err := strconv.ParseFloat(text, 64)
-		if err != nil {
+		n, err := strconv.ParseFloat(digits, 64)
+		if errors.Is(err, strconv.ErrRange) && math.IsInf(n, 0) {
+			return s.numberError("Number is too large")
+		} else if err != nil && !errors.Is(err, strconv.ErrRange) {
 			return err
 		}
 		l = internal.NewLiteralFloat(n)
 	} else {
-		n, err := strconv.ParseInt(text, 10, 64)
-		if err != nil {
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			// the literal does not fit into int64, so it is a bignum
+			b, _ := new(big.Int).SetString(digits, 10)
+			l = internal.NewLiteralBigInt(b)
+		} else if err != nil {
 			return err
+		} else {
+			l = internal.NewLiteralInt(n)
 		}
-		l = internal.NewLiteralInt(n)
 	}
 
-	s.tokens = append(s.tokens, token.New(kind.Number, text, s.line, l))
+	s.tokens = append(s.tokens, token.New(kind.Number, text, s.startLine, s.startColumn, l))
 	return nil
 }
 
-func (s *Scanner) string() error {
-	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
-		}
+// integer scans digits of the integer in the base after its prefix.
+func (s *Scanner) integer(base int) error {
+	isDigit := func(r rune) bool {
+		d, ok := digitValue(r)
+		return ok && d < base
+	}
 
+	if s.peek() == '_' {
 		_ = s.advance()
 	}
-
-	if s.isAtEnd() {
-		return SyntaxError{lineNumber: s.line, message: "Untermintaed string"}
+	if !isDigit(s.peek()) {
+		return s.numberError("Expected digits after " + string(s.source[s.start:s.current]))
+	}
+	if err := s.digits(isDigit); err != nil {
+		return err
+	}
+	if _, ok := digitValue(s.peek()); ok {
+		return s.numberError(fmt.Sprintf("Invalid digit '%c' in base %d literal", s.peek(), base))
 	}
 
-	_ = s.advance()
+	text := string(s.source[s.start:s.current])
+	b, _ := new(big.Int).SetString(strings.ReplaceAll(text[2:], "_", ""), base)
 
-	text := string(s.source[s.start+1 : s.current-1])
-	s.tokens = append(s.tokens, token.New(kind.String, text, s.line, internal.NewLiteralString(text)))
+	s.tokens = append(s.tokens, token.New(kind.Number, text, s.startLine, s.startColumn, internal.NewLiteralBigInt(b)))
 	return nil
 }
 
+// digits consumes digits; a separator '_' is allowed only between two digits.
+func (s *Scanner) digits(isDigit func(r rune) bool) error {
+	for {
+		if isDigit(s.peek()) {
+			_ = s.advance()
+		} else if s.peek() == '_' {
+			if !isDigit(s.peekNext()) {
+				return s.numberError("'_' must separate successive digits")
+			}
+			_ = s.advance()
+		} else {
+			return nil
+		}
+	}
+}
+
+func (s *Scanner) numberError(message string) error {
+	return SyntaxError{lineNumber: s.startLine, column: s.startColumn, message: message}
+}
+
+var bases = map[rune]int{'x': 16, 'o': 8, 'b': 2}
+
+func isDecimal(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// digitValue returns the value of the hexadecimal or decimal digit.
+func digitValue(r rune) (int, bool) {
+	switch {
+	case isDecimal(r):
+		return int(r - '0'), true
+	case r >= 'a' && r <= 'f':
+		return int(r-'a') + 10, true
+	case r >= 'A' && r <= 'F':
+		return int(r-'A') + 10, true
+	}
+	return 0, false
+}
+
 func (s *Scanner) appendSingleToken(_type kind.TokenType) {
-	s.tokens = append(s.tokens, token.New(_type, string(s.source[s.start:s.current]), s.line, internal.LiteralNil))
+	s.tokens = append(s.tokens, token.New(_type, string(s.source[s.start:s.current]), s.startLine, s.startColumn, internal.LiteralNil))
+}
+
+// newLine must be called after '\n' is consumed.
+func (s *Scanner) newLine() {
+	s.line++
+	s.lineStart = s.current
 }
 
 func (s *Scanner) advance() rune {
@@ -260,20 +411,29 @@ func (s *Scanner) isAtEnd() bool {
 }
 
 var keywords = map[string]kind.TokenType{
-	"and":    kind.And,
-	"or":     kind.Or,
-	"class":  kind.Class,
-	"if":     kind.If,
-	"else":   kind.Else,
-	"false":  kind.False,
-	"true":   kind.True,
-	"for":    kind.For,
-	"while":  kind.While,
-	"fun":    kind.Fun,
-	"super":  kind.Super,
-	"this":   kind.This,
-	"print":  kind.Print,
-	"return": kind.Return,
-	"var":    kind.Var,
-	"nil":    kind.Nil,
+	"and":      kind.And,
+	"break":    kind.Break,
+	"continue": kind.Continue,
+	"or":       kind.Or,
+	"class":    kind.Class,
+	"if":       kind.If,
+	"else":     kind.Else,
+	"false":    kind.False,
+	"true":     kind.True,
+	"for":      kind.For,
+	"while":    kind.While,
+	"throw":    kind.Throw,
+	"try":      kind.Try,
+	"catch":    kind.Catch,
+	"finally":  kind.Finally,
+	"import":   kind.Import,
+	"from":     kind.From,
+	"as":       kind.As,
+	"fun":      kind.Fun,
+	"super":    kind.Super,
+	"this":     kind.This,
+	"print":    kind.Print,
+	"return":   kind.Return,
+	"var":      kind.Var,
+	"nil":      kind.Nil,
 }
@@ -1,6 +1,9 @@
 package scanner
 
 import (
+	"errors"
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -19,20 +22,20 @@ func TestScanTokens(t *testing.T) {
 		{
 			in: "var kek = (1 + 2.53)/6 * lol;",
 			expected: []token.Token{
-				token.New(kind.Var, "var", 1, internal.LiteralNil),
-				token.New(kind.Identifier, "kek", 1, internal.LiteralNil),
-				token.New(kind.Equal, "=", 1, internal.LiteralNil),
-				token.New(kind.LeftParen, "(", 1, internal.LiteralNil),
-				token.New(kind.Number, "1", 1, internal.NewLiteralInt(1)),
-				token.New(kind.Plus, "+", 1, internal.LiteralNil),
-				token.New(kind.Number, "2.53", 1, internal.NewLiteralFloat(2.53)),
-				token.New(kind.RightParen, ")", 1, internal.LiteralNil),
-				token.New(kind.Slash, "/", 1, internal.LiteralNil),
-				token.New(kind.Number, "6", 1, internal.NewLiteralInt(6)),
-				token.New(kind.Star, "*", 1, internal.LiteralNil),
-				token.New(kind.Identifier, "lol", 1, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 1, internal.LiteralNil),
-				token.New(kind.EOF, "", 1, internal.LiteralNil),
+				token.New(kind.Var, "var", 1, 1, internal.LiteralNil),
+				token.New(kind.Identifier, "kek", 1, 5, internal.LiteralNil),
+				token.New(kind.Equal, "=", 1, 9, internal.LiteralNil),
+				token.New(kind.LeftParen, "(", 1, 11, internal.LiteralNil),
+				token.New(kind.Number, "1", 1, 12, internal.NewLiteralInt(1)),
+				token.New(kind.Plus, "+", 1, 14, internal.LiteralNil),
+				token.New(kind.Number, "2.53", 1, 16, internal.NewLiteralFloat(2.53)),
+				token.New(kind.RightParen, ")", 1, 20, internal.LiteralNil),
+				token.New(kind.Slash, "/", 1, 21, internal.LiteralNil),
+				token.New(kind.Number, "6", 1, 22, internal.NewLiteralInt(6)),
+				token.New(kind.Star, "*", 1, 24, internal.LiteralNil),
+				token.New(kind.Identifier, "lol", 1, 26, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 1, 29, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 30, internal.LiteralNil),
 			},
 		},
 		{
@@ -57,134 +60,134 @@ func TestScanTokens(t *testing.T) {
 				}
 			`,
 			expected: []token.Token{
-				token.New(kind.Class, "class", 2, internal.LiteralNil),
-				token.New(kind.Identifier, "Test", 2, internal.LiteralNil),
-				token.New(kind.Less, "<", 2, internal.LiteralNil),
-				token.New(kind.Identifier, "Base", 2, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 2, internal.LiteralNil),
-
-				token.New(kind.Identifier, "foo", 3, internal.LiteralNil),
-				token.New(kind.LeftParen, "(", 3, internal.LiteralNil),
-				token.New(kind.RightParen, ")", 3, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 3, internal.LiteralNil),
-
-				token.New(kind.Super, "super", 4, internal.LiteralNil),
-				token.New(kind.Dot, ".", 4, internal.LiteralNil),
-				token.New(kind.Identifier, "foo", 4, internal.LiteralNil),
-				token.New(kind.LeftParen, "(", 4, internal.LiteralNil),
-				token.New(kind.RightParen, ")", 4, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 4, internal.LiteralNil),
-
-				token.New(kind.If, "if", 6, internal.LiteralNil), // if start
-				token.New(kind.LeftParen, "(", 6, internal.LiteralNil),
-				token.New(kind.This, "this", 6, internal.LiteralNil),
-				token.New(kind.Dot, ".", 6, internal.LiteralNil),
-				token.New(kind.Identifier, "i", 6, internal.LiteralNil),
-				token.New(kind.EqualEqual, "==", 6, internal.LiteralNil),
-				token.New(kind.Number, "0", 6, internal.NewLiteralInt(0)),
-				token.New(kind.RightParen, ")", 6, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 6, internal.LiteralNil),
-				token.New(kind.Return, "return", 7, internal.LiteralNil),
-				token.New(kind.Minus, "-", 7, internal.LiteralNil),
-				token.New(kind.Number, "1", 7, internal.NewLiteralInt(1)),
-				token.New(kind.Semicolon, ";", 7, internal.LiteralNil),
-				token.New(kind.RightBrace, "}", 8, internal.LiteralNil), // if end
-
-				token.New(kind.While, "while", 10, internal.LiteralNil), // while start
-				token.New(kind.LeftParen, "(", 10, internal.LiteralNil),
-				token.New(kind.This, "this", 10, internal.LiteralNil),
-				token.New(kind.Dot, ".", 10, internal.LiteralNil),
-				token.New(kind.Identifier, "i", 10, internal.LiteralNil),
-				token.New(kind.GreaterEqual, ">=", 10, internal.LiteralNil),
-				token.New(kind.Number, "100", 10, internal.NewLiteralInt(100)),
-				token.New(kind.RightParen, ")", 10, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 10, internal.LiteralNil),
-				token.New(kind.This, "this", 11, internal.LiteralNil),
-				token.New(kind.Dot, ".", 11, internal.LiteralNil),
-				token.New(kind.Identifier, "i", 11, internal.LiteralNil),
-				token.New(kind.Equal, "=", 11, internal.LiteralNil),
-				token.New(kind.This, "this", 11, internal.LiteralNil),
-				token.New(kind.Dot, ".", 11, internal.LiteralNil),
-				token.New(kind.Identifier, "i", 11, internal.LiteralNil),
-				token.New(kind.Minus, "-", 11, internal.LiteralNil),
-				token.New(kind.This, "this", 11, internal.LiteralNil),
-				token.New(kind.Dot, ".", 11, internal.LiteralNil),
-				token.New(kind.Identifier, "j", 11, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 11, internal.LiteralNil),
-				token.New(kind.RightBrace, "}", 12, internal.LiteralNil), // while end
-
-				token.New(kind.For, "for", 13, internal.LiteralNil), // for start
-				token.New(kind.LeftParen, "(", 13, internal.LiteralNil),
-				token.New(kind.Var, "var", 13, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 13, internal.LiteralNil),
-				token.New(kind.Equal, "=", 13, internal.LiteralNil),
-				token.New(kind.Number, "0", 13, internal.NewLiteralInt(0)),
-				token.New(kind.Semicolon, ";", 13, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 13, internal.LiteralNil),
-				token.New(kind.Less, "<", 13, internal.LiteralNil),
-				token.New(kind.This, "this", 13, internal.LiteralNil),
-				token.New(kind.Dot, ".", 13, internal.LiteralNil),
-				token.New(kind.Identifier, "j", 13, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 13, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 13, internal.LiteralNil),
-				token.New(kind.Equal, "=", 13, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 13, internal.LiteralNil),
-				token.New(kind.Plus, "+", 13, internal.LiteralNil),
-				token.New(kind.Number, "1", 13, internal.NewLiteralInt(1)),
-				token.New(kind.RightParen, ")", 13, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 13, internal.LiteralNil),
-
-				token.New(kind.If, "if", 14, internal.LiteralNil), // if start
-				token.New(kind.LeftParen, "(", 14, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 14, internal.LiteralNil),
-				token.New(kind.Slash, "/", 14, internal.LiteralNil),
-				token.New(kind.Number, "2", 14, internal.NewLiteralInt(2)),
-				token.New(kind.BangEqual, "!=", 14, internal.LiteralNil),
-				token.New(kind.Number, "0", 14, internal.NewLiteralInt(0)),
-				token.New(kind.RightParen, ")", 14, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 14, internal.LiteralNil),
-				token.New(kind.Print, "print", 15, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 15, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 15, internal.LiteralNil),
-				token.New(kind.RightBrace, "}", 16, internal.LiteralNil), // if end
-
-				token.New(kind.RightBrace, "}", 17, internal.LiteralNil), // for end
-
-				token.New(kind.RightBrace, "}", 18, internal.LiteralNil), // foo end
-				token.New(kind.RightBrace, "}", 19, internal.LiteralNil), // class end
-
-				token.New(kind.EOF, "", 20, internal.LiteralNil),
+				token.New(kind.Class, "class", 2, 5, internal.LiteralNil),
+				token.New(kind.Identifier, "Test", 2, 11, internal.LiteralNil),
+				token.New(kind.Less, "<", 2, 16, internal.LiteralNil),
+				token.New(kind.Identifier, "Base", 2, 18, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 2, 23, internal.LiteralNil),
+
+				token.New(kind.Identifier, "foo", 3, 6, internal.LiteralNil),
+				token.New(kind.LeftParen, "(", 3, 9, internal.LiteralNil),
+				token.New(kind.RightParen, ")", 3, 10, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 3, 12, internal.LiteralNil),
+
+				token.New(kind.Super, "super", 4, 7, internal.LiteralNil),
+				token.New(kind.Dot, ".", 4, 12, internal.LiteralNil),
+				token.New(kind.Identifier, "foo", 4, 13, internal.LiteralNil),
+				token.New(kind.LeftParen, "(", 4, 16, internal.LiteralNil),
+				token.New(kind.RightParen, ")", 4, 17, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 4, 18, internal.LiteralNil),
+
+				token.New(kind.If, "if", 6, 7, internal.LiteralNil), // if start
+				token.New(kind.LeftParen, "(", 6, 10, internal.LiteralNil),
+				token.New(kind.This, "this", 6, 11, internal.LiteralNil),
+				token.New(kind.Dot, ".", 6, 15, internal.LiteralNil),
+				token.New(kind.Identifier, "i", 6, 16, internal.LiteralNil),
+				token.New(kind.EqualEqual, "==", 6, 18, internal.LiteralNil),
+				token.New(kind.Number, "0", 6, 21, internal.NewLiteralInt(0)),
+				token.New(kind.RightParen, ")", 6, 22, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 6, 24, internal.LiteralNil),
+				token.New(kind.Return, "return", 7, 8, internal.LiteralNil),
+				token.New(kind.Minus, "-", 7, 15, internal.LiteralNil),
+				token.New(kind.Number, "1", 7, 16, internal.NewLiteralInt(1)),
+				token.New(kind.Semicolon, ";", 7, 17, internal.LiteralNil),
+				token.New(kind.RightBrace, "}", 8, 7, internal.LiteralNil), // if end
+
+				token.New(kind.While, "while", 10, 7, internal.LiteralNil), // while start
+				token.New(kind.LeftParen, "(", 10, 13, internal.LiteralNil),
+				token.New(kind.This, "this", 10, 14, internal.LiteralNil),
+				token.New(kind.Dot, ".", 10, 18, internal.LiteralNil),
+				token.New(kind.Identifier, "i", 10, 19, internal.LiteralNil),
+				token.New(kind.GreaterEqual, ">=", 10, 21, internal.LiteralNil),
+				token.New(kind.Number, "100", 10, 24, internal.NewLiteralInt(100)),
+				token.New(kind.RightParen, ")", 10, 27, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 10, 29, internal.LiteralNil),
+				token.New(kind.This, "this", 11, 8, internal.LiteralNil),
+				token.New(kind.Dot, ".", 11, 12, internal.LiteralNil),
+				token.New(kind.Identifier, "i", 11, 13, internal.LiteralNil),
+				token.New(kind.Equal, "=", 11, 15, internal.LiteralNil),
+				token.New(kind.This, "this", 11, 17, internal.LiteralNil),
+				token.New(kind.Dot, ".", 11, 21, internal.LiteralNil),
+				token.New(kind.Identifier, "i", 11, 22, internal.LiteralNil),
+				token.New(kind.Minus, "-", 11, 24, internal.LiteralNil),
+				token.New(kind.This, "this", 11, 26, internal.LiteralNil),
+				token.New(kind.Dot, ".", 11, 30, internal.LiteralNil),
+				token.New(kind.Identifier, "j", 11, 31, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 11, 32, internal.LiteralNil),
+				token.New(kind.RightBrace, "}", 12, 7, internal.LiteralNil), // while end
+
+				token.New(kind.For, "for", 13, 7, internal.LiteralNil), // for start
+				token.New(kind.LeftParen, "(", 13, 11, internal.LiteralNil),
+				token.New(kind.Var, "var", 13, 12, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 13, 16, internal.LiteralNil),
+				token.New(kind.Equal, "=", 13, 18, internal.LiteralNil),
+				token.New(kind.Number, "0", 13, 20, internal.NewLiteralInt(0)),
+				token.New(kind.Semicolon, ";", 13, 21, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 13, 23, internal.LiteralNil),
+				token.New(kind.Less, "<", 13, 25, internal.LiteralNil),
+				token.New(kind.This, "this", 13, 27, internal.LiteralNil),
+				token.New(kind.Dot, ".", 13, 31, internal.LiteralNil),
+				token.New(kind.Identifier, "j", 13, 32, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 13, 33, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 13, 35, internal.LiteralNil),
+				token.New(kind.Equal, "=", 13, 37, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 13, 39, internal.LiteralNil),
+				token.New(kind.Plus, "+", 13, 41, internal.LiteralNil),
+				token.New(kind.Number, "1", 13, 43, internal.NewLiteralInt(1)),
+				token.New(kind.RightParen, ")", 13, 44, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 13, 46, internal.LiteralNil),
+
+				token.New(kind.If, "if", 14, 8, internal.LiteralNil), // if start
+				token.New(kind.LeftParen, "(", 14, 11, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 14, 12, internal.LiteralNil),
+				token.New(kind.Slash, "/", 14, 14, internal.LiteralNil),
+				token.New(kind.Number, "2", 14, 16, internal.NewLiteralInt(2)),
+				token.New(kind.BangEqual, "!=", 14, 18, internal.LiteralNil),
+				token.New(kind.Number, "0", 14, 21, internal.NewLiteralInt(0)),
+				token.New(kind.RightParen, ")", 14, 22, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 14, 24, internal.LiteralNil),
+				token.New(kind.Print, "print", 15, 9, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 15, 15, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 15, 16, internal.LiteralNil),
+				token.New(kind.RightBrace, "}", 16, 8, internal.LiteralNil), // if end
+
+				token.New(kind.RightBrace, "}", 17, 7, internal.LiteralNil), // for end
+
+				token.New(kind.RightBrace, "}", 18, 6, internal.LiteralNil), // foo end
+				token.New(kind.RightBrace, "}", 19, 5, internal.LiteralNil), // class end
+
+				token.New(kind.EOF, "", 20, 4, internal.LiteralNil),
 			},
 		},
 		{
 			in: `if ("str" == "str" and 1 != 1 or k == nil) { print true; } else { print false; }`,
 			expected: []token.Token{
-				token.New(kind.If, "if", 1, internal.LiteralNil), // if start
-				token.New(kind.LeftParen, "(", 1, internal.LiteralNil),
-				token.New(kind.String, "str", 1, internal.NewLiteralString("str")),
-				token.New(kind.EqualEqual, "==", 1, internal.LiteralNil),
-				token.New(kind.String, "str", 1, internal.NewLiteralString("str")),
-				token.New(kind.And, "and", 1, internal.LiteralNil),
-				token.New(kind.Number, "1", 1, internal.NewLiteralInt(1)),
-				token.New(kind.BangEqual, "!=", 1, internal.LiteralNil),
-				token.New(kind.Number, "1", 1, internal.NewLiteralInt(1)),
-				token.New(kind.Or, "or", 1, internal.LiteralNil),
-				token.New(kind.Identifier, "k", 1, internal.LiteralNil),
-				token.New(kind.EqualEqual, "==", 1, internal.LiteralNil),
-				token.New(kind.Nil, "nil", 1, internal.LiteralNil),
-				token.New(kind.RightParen, ")", 1, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 1, internal.LiteralNil), // if body start
-				token.New(kind.Print, "print", 1, internal.LiteralNil),
-				token.New(kind.True, "true", 1, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 1, internal.LiteralNil),
-				token.New(kind.RightBrace, "}", 1, internal.LiteralNil), // if body end
-				token.New(kind.Else, "else", 1, internal.LiteralNil),
-				token.New(kind.LeftBrace, "{", 1, internal.LiteralNil), // else start
-				token.New(kind.Print, "print", 1, internal.LiteralNil),
-				token.New(kind.False, "false", 1, internal.LiteralNil),
-				token.New(kind.Semicolon, ";", 1, internal.LiteralNil),
-				token.New(kind.RightBrace, "}", 1, internal.LiteralNil), // else end
-				token.New(kind.EOF, "", 1, internal.LiteralNil),
+				token.New(kind.If, "if", 1, 1, internal.LiteralNil), // if start
+				token.New(kind.LeftParen, "(", 1, 4, internal.LiteralNil),
+				token.New(kind.String, "str", 1, 5, internal.NewLiteralString("str")),
+				token.New(kind.EqualEqual, "==", 1, 11, internal.LiteralNil),
+				token.New(kind.String, "str", 1, 14, internal.NewLiteralString("str")),
+				token.New(kind.And, "and", 1, 20, internal.LiteralNil),
+				token.New(kind.Number, "1", 1, 24, internal.NewLiteralInt(1)),
+				token.New(kind.BangEqual, "!=", 1, 26, internal.LiteralNil),
+				token.New(kind.Number, "1", 1, 29, internal.NewLiteralInt(1)),
+				token.New(kind.Or, "or", 1, 31, internal.LiteralNil),
+				token.New(kind.Identifier, "k", 1, 34, internal.LiteralNil),
+				token.New(kind.EqualEqual, "==", 1, 36, internal.LiteralNil),
+				token.New(kind.Nil, "nil", 1, 39, internal.LiteralNil),
+				token.New(kind.RightParen, ")", 1, 42, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 1, 44, internal.LiteralNil), // if body start
+				token.New(kind.Print, "print", 1, 46, internal.LiteralNil),
+				token.New(kind.True, "true", 1, 52, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 1, 56, internal.LiteralNil),
+				token.New(kind.RightBrace, "}", 1, 58, internal.LiteralNil), // if body end
+				token.New(kind.Else, "else", 1, 60, internal.LiteralNil),
+				token.New(kind.LeftBrace, "{", 1, 65, internal.LiteralNil), // else start
+				token.New(kind.Print, "print", 1, 67, internal.LiteralNil),
+				token.New(kind.False, "false", 1, 73, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 1, 78, internal.LiteralNil),
+				token.New(kind.RightBrace, "}", 1, 80, internal.LiteralNil), // else end
+				token.New(kind.EOF, "", 1, 81, internal.LiteralNil),
 			},
 		},
 		{
@@ -210,29 +213,248 @@ func TestScanTokens(t *testing.T) {
 			print "success";
 			`,
 			expected: []token.Token{
-				token.New(kind.Print, "print", 20, internal.LiteralNil),
-				token.New(kind.String, "success", 20, internal.NewLiteralString("success")),
-				token.New(kind.Semicolon, ";", 20, internal.LiteralNil),
-				token.New(kind.EOF, "", 21, internal.LiteralNil),
+				token.New(kind.Print, "print", 20, 4, internal.LiteralNil),
+				token.New(kind.String, "success", 20, 10, internal.NewLiteralString("success")),
+				token.New(kind.Semicolon, ";", 20, 19, internal.LiteralNil),
+				token.New(kind.EOF, "", 21, 4, internal.LiteralNil),
 			},
 		},
 		{
 			in: "var a = 5 & 4 | 3 ^ ~2",
 			expected: []token.Token{
-				token.New(kind.Var, "var", 1, internal.LiteralNil),
-				token.New(kind.Identifier, "a", 1, internal.LiteralNil),
-				token.New(kind.Equal, "=", 1, internal.LiteralNil),
-				token.New(kind.Number, "5", 1, internal.NewLiteralInt(5)),
-				token.New(kind.BitwiseAnd, "&", 1, internal.LiteralNil),
-				token.New(kind.Number, "4", 1, internal.NewLiteralInt(4)),
-				token.New(kind.BitwiseOr, "|", 1, internal.LiteralNil),
-				token.New(kind.Number, "3", 1, internal.NewLiteralInt(3)),
-				token.New(kind.BitwiseXor, "^", 1, internal.LiteralNil),
-				token.New(kind.BitwiseNot, "~", 1, internal.LiteralNil),
-				token.New(kind.Number, "2", 1, internal.NewLiteralInt(2)),
-				token.New(kind.EOF, "", 1, internal.LiteralNil),
+				token.New(kind.Var, "var", 1, 1, internal.LiteralNil),
+				token.New(kind.Identifier, "a", 1, 5, internal.LiteralNil),
+				token.New(kind.Equal, "=", 1, 7, internal.LiteralNil),
+				token.New(kind.Number, "5", 1, 9, internal.NewLiteralInt(5)),
+				token.New(kind.BitwiseAnd, "&", 1, 11, internal.LiteralNil),
+				token.New(kind.Number, "4", 1, 13, internal.NewLiteralInt(4)),
+				token.New(kind.BitwiseOr, "|", 1, 15, internal.LiteralNil),
+				token.New(kind.Number, "3", 1, 17, internal.NewLiteralInt(3)),
+				token.New(kind.BitwiseXor, "^", 1, 19, internal.LiteralNil),
+				token.New(kind.BitwiseNot, "~", 1, 21, internal.LiteralNil),
+				token.New(kind.Number, "2", 1, 22, internal.NewLiteralInt(2)),
+				token.New(kind.EOF, "", 1, 23, internal.LiteralNil),
+			},
+		},
+		{
+			in: "break; continue;",
+			expected: []token.Token{
+				token.New(kind.Break, "break", 1, 1, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 1, 6, internal.LiteralNil),
+				token.New(kind.Continue, "continue", 1, 8, internal.LiteralNil),
+				token.New(kind.Semicolon, ";", 1, 16, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 17, internal.LiteralNil),
+			},
+		},
+		{
+			in: "xs[1:2]",
+			expected: []token.Token{
+				token.New(kind.Identifier, "xs", 1, 1, internal.LiteralNil),
+				token.New(kind.LeftBracket, "[", 1, 3, internal.LiteralNil),
+				token.New(kind.Number, "1", 1, 4, internal.NewLiteralInt(1)),
+				token.New(kind.Colon, ":", 1, 5, internal.LiteralNil),
+				token.New(kind.Number, "2", 1, 6, internal.NewLiteralInt(2)),
+				token.New(kind.RightBracket, "]", 1, 7, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 8, internal.LiteralNil),
+			},
+		},
+		{
+			in: "import from as",
+			expected: []token.Token{
+				token.New(kind.Import, "import", 1, 1, internal.LiteralNil),
+				token.New(kind.From, "from", 1, 8, internal.LiteralNil),
+				token.New(kind.As, "as", 1, 13, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 15, internal.LiteralNil),
+			},
+		},
+		{
+			in: "try catch finally throw",
+			expected: []token.Token{
+				token.New(kind.Try, "try", 1, 1, internal.LiteralNil),
+				token.New(kind.Catch, "catch", 1, 5, internal.LiteralNil),
+				token.New(kind.Finally, "finally", 1, 11, internal.LiteralNil),
+				token.New(kind.Throw, "throw", 1, 19, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 24, internal.LiteralNil),
+			},
+		},
+		{
+			in: "a => b == c",
+			expected: []token.Token{
+				token.New(kind.Identifier, "a", 1, 1, internal.LiteralNil),
+				token.New(kind.Arrow, "=>", 1, 3, internal.LiteralNil),
+				token.New(kind.Identifier, "b", 1, 6, internal.LiteralNil),
+				token.New(kind.EqualEqual, "==", 1, 8, internal.LiteralNil),
+				token.New(kind.Identifier, "c", 1, 11, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 12, internal.LiteralNil),
+			},
+		},
+		{
+			in: "a % b ** c << d >> e <= f >= g",
+			expected: []token.Token{
+				token.New(kind.Identifier, "a", 1, 1, internal.LiteralNil),
+				token.New(kind.Percent, "%", 1, 3, internal.LiteralNil),
+				token.New(kind.Identifier, "b", 1, 5, internal.LiteralNil),
+				token.New(kind.StarStar, "**", 1, 7, internal.LiteralNil),
+				token.New(kind.Identifier, "c", 1, 10, internal.LiteralNil),
+				token.New(kind.LessLess, "<<", 1, 12, internal.LiteralNil),
+				token.New(kind.Identifier, "d", 1, 15, internal.LiteralNil),
+				token.New(kind.GreaterGreater, ">>", 1, 17, internal.LiteralNil),
+				token.New(kind.Identifier, "e", 1, 20, internal.LiteralNil),
+				token.New(kind.LessEqual, "<=", 1, 22, internal.LiteralNil),
+				token.New(kind.Identifier, "f", 1, 25, internal.LiteralNil),
+				token.New(kind.GreaterEqual, ">=", 1, 27, internal.LiteralNil),
+				token.New(kind.Identifier, "g", 1, 30, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 31, internal.LiteralNil),
+			},
+		},
+		{
+			in: "9223372036854775807 9223372036854775808",
+			expected: []token.Token{
+				token.New(kind.Number, "9223372036854775807", 1, 1, internal.NewLiteralInt(math.MaxInt64)),
+				token.New(kind.Number, "9223372036854775808", 1, 21, internal.NewLiteralBigInt(new(big.Int).SetUint64(1<<63))),
+				token.New(kind.EOF, "", 1, 40, internal.LiteralNil),
+			},
+		},
+		{
+			in: "0xFF 0X_dead_BEEF",
+			expected: []token.Token{
+				token.New(kind.Number, "0xFF", 1, 1, internal.NewLiteralInt(255)),
+				token.New(kind.Number, "0X_dead_BEEF", 1, 6, internal.NewLiteralInt(0xdeadbeef)),
+				token.New(kind.EOF, "", 1, 18, internal.LiteralNil),
+			},
+		},
+		{
+			in: "0b1010 0B1_0",
+			expected: []token.Token{
+				token.New(kind.Number, "0b1010", 1, 1, internal.NewLiteralInt(10)),
+				token.New(kind.Number, "0B1_0", 1, 8, internal.NewLiteralInt(2)),
+				token.New(kind.EOF, "", 1, 13, internal.LiteralNil),
+			},
+		},
+		{
+			in: "0o17 0O7_7",
+			expected: []token.Token{
+				token.New(kind.Number, "0o17", 1, 1, internal.NewLiteralInt(15)),
+				token.New(kind.Number, "0O7_7", 1, 6, internal.NewLiteralInt(63)),
+				token.New(kind.EOF, "", 1, 11, internal.LiteralNil),
+			},
+		},
+		{
+			in: "1_000_000 3.141_5",
+			expected: []token.Token{
+				token.New(kind.Number, "1_000_000", 1, 1, internal.NewLiteralInt(1000000)),
+				token.New(kind.Number, "3.141_5", 1, 11, internal.NewLiteralFloat(3.1415)),
+				token.New(kind.EOF, "", 1, 18, internal.LiteralNil),
+			},
+		},
+		{
+			in: "6.02e23 1E3 2.5e-3 1e+1_0",
+			expected: []token.Token{
+				token.New(kind.Number, "6.02e23", 1, 1, internal.NewLiteralFloat(6.02e23)),
+				token.New(kind.Number, "1E3", 1, 9, internal.NewLiteralFloat(1000)),
+				token.New(kind.Number, "2.5e-3", 1, 13, internal.NewLiteralFloat(0.0025)),
+				token.New(kind.Number, "1e+1_0", 1, 20, internal.NewLiteralFloat(1e10)),
+				token.New(kind.EOF, "", 1, 26, internal.LiteralNil),
+			},
+		},
+		{
+			in: "0x1_0000_0000_0000_0000",
+			expected: []token.Token{
+				token.New(kind.Number, "0x1_0000_0000_0000_0000", 1, 1, internal.NewLiteralBigInt(new(big.Int).Lsh(big.NewInt(1), 64))),
+				token.New(kind.EOF, "", 1, 24, internal.LiteralNil),
+			},
+		},
+		{
+			// e without digits is not an exponent
+			in: "1e",
+			expected: []token.Token{
+				token.New(kind.Number, "1", 1, 1, internal.NewLiteralInt(1)),
+				token.New(kind.Identifier, "e", 1, 2, internal.LiteralNil),
+				token.New(kind.EOF, "", 1, 3, internal.LiteralNil),
+			},
+		},
+		{
+			in: `"a\tb\n\"c\" \\ \u{1F600}\u{41}\0"`,
+			expected: []token.Token{
+				token.New(kind.String, `a\tb\n\"c\" \\ \u{1F600}\u{41}\0`, 1, 1, internal.NewLiteralString("a\tb\n\"c\" \\ 😀A\x00")),
+				token.New(kind.EOF, "", 1, 35, internal.LiteralNil),
+			},
+		},
+		{
+			in: "`a\\n\"b\"\nc` x",
+			expected: []token.Token{
+				token.New(kind.String, "a\\n\"b\"\nc", 1, 1, internal.NewLiteralString("a\\n\"b\"\nc")),
+				token.New(kind.Identifier, "x", 2, 4, internal.LiteralNil),
+				token.New(kind.EOF, "", 2, 5, internal.LiteralNil),
+			},
+		},
+		{
+			in: "x = \"\"\"\n    one\n      two\\t\n\n    three\n    \"\"\";\ny",
+			expected: []token.Token{
+				token.New(kind.Identifier, "x", 1, 1, internal.LiteralNil),
+				token.New(kind.Equal, "=", 1, 3, internal.LiteralNil),
+				token.New(kind.String, "\n    one\n      two\\t\n\n    three\n    ", 1, 5, internal.NewLiteralString("one\n  two\t\n\nthree")),
+				token.New(kind.Semicolon, ";", 6, 8, internal.LiteralNil),
+				token.New(kind.Identifier, "y", 7, 1, internal.LiteralNil),
+				token.New(kind.EOF, "", 7, 2, internal.LiteralNil),
+			},
+		},
+		{
+			in: `"""say "hi" """ ""`,
+			expected: []token.Token{
+				token.New(kind.String, `say "hi" `, 1, 1, internal.NewLiteralString(`say "hi" `)),
+				token.New(kind.String, "", 1, 17, internal.NewLiteralString("")),
+				token.New(kind.EOF, "", 1, 19, internal.LiteralNil),
+			},
+		},
+		{
+			in: `"a ${b + "c${d}"} {e}"`,
+			expected: []token.Token{
+				token.New(kind.Interpolation, "a ", 1, 1, internal.NewLiteralString("a ")),
+				token.New(kind.Identifier, "b", 1, 6, internal.LiteralNil),
+				token.New(kind.Plus, "+", 1, 8, internal.LiteralNil),
+				token.New(kind.Interpolation, "c", 1, 10, internal.NewLiteralString("c")),
+				token.New(kind.Identifier, "d", 1, 14, internal.LiteralNil),
+				token.New(kind.String, "", 1, 15, internal.NewLiteralString("")),
+				token.New(kind.String, " {e}", 1, 17, internal.NewLiteralString(" {e}")),
+				token.New(kind.EOF, "", 1, 23, internal.LiteralNil),
+			},
+		},
+		{
+			in: `"${ {"k": 1}["k"] }\n\${x}"`,
+			expected: []token.Token{
+				token.New(kind.Interpolation, "", 1, 1, internal.NewLiteralString("")),
+				token.New(kind.LeftBrace, "{", 1, 5, internal.LiteralNil),
+				token.New(kind.String, "k", 1, 6, internal.NewLiteralString("k")),
+				token.New(kind.Colon, ":", 1, 9, internal.LiteralNil),
+				token.New(kind.Number, "1", 1, 11, internal.NewLiteralInt(1)),
+				token.New(kind.RightBrace, "}", 1, 12, internal.LiteralNil),
+				token.New(kind.LeftBracket, "[", 1, 13, internal.LiteralNil),
+				token.New(kind.String, "k", 1, 14, internal.NewLiteralString("k")),
+				token.New(kind.RightBracket, "]", 1, 17, internal.LiteralNil),
+				token.New(kind.String, `\n\${x}`, 1, 19, internal.NewLiteralString("\n${x}")),
+				token.New(kind.EOF, "", 1, 28, internal.LiteralNil),
 			},
 		},
+		{in: `"a ${}"`, err: errors.New("1:6\t|\tError: Expect expression in string interpolation")},
+		{in: "\"a\n ${b", err: errors.New("2:2\t|\tError: Unterminated string interpolation")},
+		{in: `"a\q"`, err: errors.New("1:3\t|\tError: Unknown escape sequence '\\q'")},
+		{in: "\"a\n  \\u{110000}\"", err: errors.New("2:3\t|\tError: Invalid unicode code point U+110000")},
+		{in: `"\u{D800}"`, err: errors.New("Invalid unicode code point U+D800")},
+		{in: `"\u{}"`, err: errors.New(`1:2` + "\t|\tError: Invalid unicode escape")},
+		{in: `"\u{1234567}"`, err: errors.New("Invalid unicode escape")},
+		{in: `"\u41"`, err: errors.New("Invalid unicode escape")},
+		{in: `"abc`, err: errors.New("Untermintaed string")},
+		{in: "`abc", err: errors.New("Untermintaed string")},
+		{in: `"""abc""`, err: errors.New("Untermintaed string")},
+		{in: "\"\"\"\n  a\n  \\x\"\"\"", err: errors.New("3:3\t|\tError: Unknown escape sequence '\\x'")},
+		{in: "0x", err: errors.New("Expected digits after 0x")},
+		{in: "0b12", err: errors.New("Invalid digit '2' in base 2 literal")},
+		{in: "0o8", err: errors.New("Expected digits after 0o")},
+		{in: "1__0", err: errors.New("'_' must separate successive digits")},
+		{in: "1_", err: errors.New("'_' must separate successive digits")},
+		{in: "1_.5", err: errors.New("'_' must separate successive digits")},
+		{in: "1e400", err: errors.New("Number is too large")},
 	}
 
 	for _, args := range tests {
@@ -240,7 +462,7 @@ func TestScanTokens(t *testing.T) {
 		actually, err := s.ScanTokens()
 
 		if args.err != nil {
-			require.Error(t, err)
+			require.ErrorContains(t, err, args.err.Error())
 		} else {
 			require.Equal(t, args.expected, actually)
 		}
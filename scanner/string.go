@@ -0,0 +1,247 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/token"
+	"github.com/nikgalushko/gan-ilox/token/kind"
+)
+
+// string scans the string with escape sequences; it may span lines.
+// The string is split by embedded expressions `${expr}`: every part before an expression
+// is the Interpolation token and the last part is the String token.
+// The scanning starts after the opening quote or after '}' which closes the expression.
+func (s *Scanner) string() error {
+	for s.peek() != '"' && !s.isAtEnd() {
+		if s.peek() == '$' && s.peekNext() == '{' {
+			break
+		}
+		if s.advance() == '\\' && !s.isAtEnd() {
+			_ = s.advance()
+		}
+		if s.source[s.current-1] == '\n' {
+			s.newLine()
+		}
+	}
+
+	if s.isAtEnd() {
+		return SyntaxError{lineNumber: s.startLine, column: s.startColumn, message: "Untermintaed string"}
+	}
+
+	var b strings.Builder
+	if err := s.unescape(&b, s.start+1, s.current); err != nil {
+		return err
+	}
+
+	if s.peek() == '$' {
+		s.tokens = append(s.tokens, token.New(kind.Interpolation, string(s.source[s.start+1:s.current]), s.startLine, s.startColumn, internal.NewLiteralString(b.String())))
+		s.interpolations = append(s.interpolations, interpolation{line: s.line, column: s.current - s.lineStart + 1})
+		s.current += 2
+		return nil
+	}
+
+	_ = s.advance()
+	s.appendString(s.start+1, s.current-1, b.String())
+	return nil
+}
+
+// rawString scans the string between backticks as is.
+func (s *Scanner) rawString() error {
+	for s.peek() != '`' && !s.isAtEnd() {
+		if s.advance() == '\n' {
+			s.newLine()
+		}
+	}
+
+	if s.isAtEnd() {
+		return SyntaxError{lineNumber: s.startLine, column: s.startColumn, message: "Untermintaed string"}
+	}
+
+	_ = s.advance()
+
+	text := string(s.source[s.start+1 : s.current-1])
+	s.appendString(s.start+1, s.current-1, text)
+	return nil
+}
+
+// textBlock scans the string between triple quotes which has escape sequences but no embedded expressions.
+// The line break after the opening quotes and the last line before the closing quotes
+// are removed if they are blank; the common indentation of non-blank lines is removed too.
+func (s *Scanner) textBlock() error {
+	from := s.current
+	for !s.isAtEnd() && !(s.peek() == '"' && s.peekNext() == '"' && s.current+2 < len(s.source) && s.source[s.current+2] == '"') {
+		if s.advance() == '\\' && !s.isAtEnd() {
+			_ = s.advance()
+		}
+		if s.source[s.current-1] == '\n' {
+			s.newLine()
+		}
+	}
+
+	if s.isAtEnd() {
+		return SyntaxError{lineNumber: s.startLine, column: s.startColumn, message: "Untermintaed string"}
+	}
+
+	to := s.current
+	s.current += 3
+
+	// lines are ranges of source indices
+	var lines [][2]int
+	for i, start := from, from; i <= to; i++ {
+		if i == to || s.source[i] == '\n' {
+			lines = append(lines, [2]int{start, i})
+			start = i + 1
+		}
+	}
+	if len(lines) > 1 && s.isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	if len(lines) > 1 && s.isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+
+	// the common indentation must consist of the same whitespace
+	var indent []rune
+	for _, l := range lines {
+		if s.isBlank(l) {
+			continue
+		}
+		prefix := s.source[l[0] : l[0]+s.indentation(l)]
+		if indent == nil {
+			indent = prefix
+			continue
+		}
+		n := 0
+		for n < len(indent) && n < len(prefix) && indent[n] == prefix[n] {
+			n++
+		}
+		indent = indent[:n]
+	}
+
+	var b strings.Builder
+	for i, l := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if s.isBlank(l) {
+			continue
+		}
+		if err := s.unescape(&b, l[0]+len(indent), l[1]); err != nil {
+			return err
+		}
+	}
+
+	s.appendString(from, to, b.String())
+	return nil
+}
+
+func (s *Scanner) isBlank(line [2]int) bool {
+	return s.indentation(line) == line[1]-line[0]
+}
+
+func (s *Scanner) indentation(line [2]int) int {
+	n := 0
+	for line[0]+n < line[1] && (s.source[line[0]+n] == ' ' || s.source[line[0]+n] == '\t' || s.source[line[0]+n] == '\r') {
+		n++
+	}
+	return n
+}
+
+// appendString appends the string token; its lexeme is the source between from and to.
+func (s *Scanner) appendString(from, to int, value string) {
+	s.tokens = append(s.tokens, token.New(kind.String, string(s.source[from:to]), s.startLine, s.startColumn, internal.NewLiteralString(value)))
+}
+
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'$':  '$',
+}
+
+// unescape writes the source between from and to with replaced escape sequences.
+func (s *Scanner) unescape(b *strings.Builder, from, to int) error {
+	for i := from; i < to; i++ {
+		r := s.source[i]
+		if r != '\\' {
+			b.WriteRune(r)
+			continue
+		}
+
+		if i+1 < to && s.source[i+1] == 'u' {
+			n, err := s.unicodeEscape(i, to)
+			if err != nil {
+				return err
+			}
+			b.WriteRune(n)
+			i = i + 1
+			for s.source[i] != '}' {
+				i++
+			}
+			continue
+		}
+
+		if i+1 == to {
+			return s.errorAt(i, "Unterminated escape sequence")
+		}
+		escaped, ok := escapes[s.source[i+1]]
+		if !ok {
+			return s.errorAt(i, fmt.Sprintf("Unknown escape sequence '\\%s'", string(s.source[i+1:i+2])))
+		}
+		b.WriteRune(escaped)
+		i++
+	}
+
+	return nil
+}
+
+// unicodeEscape decodes \u{X} where X is from 1 to 6 hex digits of the code point; i is the index of '\'.
+func (s *Scanner) unicodeEscape(i, to int) (rune, error) {
+	invalid := s.errorAt(i, `Invalid unicode escape, expected \u{X} where X is from 1 to 6 hex digits`)
+	if i+2 >= to || s.source[i+2] != '{' {
+		return 0, invalid
+	}
+
+	var n rune
+	digits := 0
+	for j := i + 3; j < to; j++ {
+		if s.source[j] == '}' {
+			if digits == 0 {
+				return 0, invalid
+			}
+			if !utf8.ValidRune(n) {
+				return 0, s.errorAt(i, fmt.Sprintf("Invalid unicode code point U+%X", n))
+			}
+			return n, nil
+		}
+
+		d, ok := digitValue(s.source[j])
+		if !ok || digits == 6 {
+			return 0, invalid
+		}
+		n = n*16 + rune(d)
+		digits++
+	}
+
+	return 0, invalid
+}
+
+// errorAt returns the syntax error at the index of the current token.
+func (s *Scanner) errorAt(idx int, message string) error {
+	line, lineStart := s.startLine, s.start-s.startColumn+1
+	for i := s.start; i < idx; i++ {
+		if s.source[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+
+	return SyntaxError{lineNumber: line, column: idx - lineStart + 1, message: message}
+}
@@ -9,6 +9,9 @@ const (
 	RightParen
 	LeftBrace
 	RightBrace
+	LeftBracket
+	RightBracket
+	Colon
 	Comma
 	Dot
 	Minus
@@ -20,6 +23,7 @@ const (
 	BitwiseOr
 	BitwiseXor
 	BitwiseNot
+	Percent
 
 	// One or two character tokens
 	Bang
@@ -30,15 +34,23 @@ const (
 	GreaterEqual
 	Less
 	LessEqual
+	LessLess
+	GreaterGreater
+	StarStar
+	Arrow
 
 	// Literals
 	Identifier
 	String
+	// Interpolation is a part of the string before the embedded expression
+	Interpolation
 	Number
 
 	// Keywords
 	And
+	Break
 	Class
+	Continue
 	Else
 	False
 	Fun
@@ -53,6 +65,13 @@ const (
 	True
 	Var
 	While
+	Throw
+	Try
+	Catch
+	Finally
+	Import
+	From
+	As
 
 	EOF
 )
@@ -67,6 +86,12 @@ func (t TokenType) String() string {
 		return "{"
 	case RightBrace:
 		return "}"
+	case LeftBracket:
+		return "["
+	case RightBracket:
+		return "]"
+	case Colon:
+		return ":"
 	case Comma:
 		return ","
 	case Dot:
@@ -81,6 +106,16 @@ func (t TokenType) String() string {
 		return "/"
 	case Star:
 		return "*"
+	case BitwiseAnd:
+		return "&"
+	case BitwiseOr:
+		return "|"
+	case BitwiseXor:
+		return "^"
+	case BitwiseNot:
+		return "~"
+	case Percent:
+		return "%"
 
 	// One or two character tokens
 	case Bang:
@@ -99,20 +134,34 @@ func (t TokenType) String() string {
 		return "<"
 	case LessEqual:
 		return "<="
+	case LessLess:
+		return "<<"
+	case GreaterGreater:
+		return ">>"
+	case StarStar:
+		return "**"
+	case Arrow:
+		return "=>"
 
 	// Literals
 	case Identifier:
 		return "identifier"
 	case String:
 		return "string"
+	case Interpolation:
+		return "interpolation"
 	case Number:
 		return "number"
 
 	// Keywords
 	case And:
 		return "and"
+	case Break:
+		return "break"
 	case Class:
 		return "class"
+	case Continue:
+		return "continue"
 	case Else:
 		return "else"
 	case False:
@@ -141,6 +190,20 @@ func (t TokenType) String() string {
 		return "var"
 	case While:
 		return "while"
+	case Throw:
+		return "throw"
+	case Try:
+		return "try"
+	case Catch:
+		return "catch"
+	case Finally:
+		return "finally"
+	case Import:
+		return "import"
+	case From:
+		return "from"
+	case As:
+		return "as"
 
 	case EOF:
 		return "EOF"
@@ -11,18 +11,24 @@ type Token struct {
 	Type    kind.TokenType
 	Lexeme  string
 	Line    int
+	Column  int
 	Literal internal.Literal
 }
 
-func New(_type kind.TokenType, lexeme string, line int, l internal.Literal) Token {
+func New(_type kind.TokenType, lexeme string, line, column int, l internal.Literal) Token {
 	return Token{
 		Type:    _type,
 		Lexeme:  lexeme,
 		Line:    line,
+		Column:  column,
 		Literal: l,
 	}
 }
 
+func (t Token) Position() internal.Position {
+	return internal.Position{Line: t.Line, Column: t.Column}
+}
+
 func (t Token) String() string {
 	return fmt.Sprintf("Type: %+v; Literal: %+v", t.Type, t.Literal)
 }
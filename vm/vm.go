@@ -0,0 +1,737 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nikgalushko/gan-ilox/compiler"
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/nikgalushko/gan-ilox/modules"
+	"github.com/nikgalushko/gan-ilox/token/kind"
+)
+
+const (
+	// scriptFrame is a name of the frame which executes top-level statements.
+	scriptFrame = "<script>"
+	// anonymousFrame is a name of the frame of an anonymous function.
+	anonymousFrame = "<anonymous>"
+)
+
+type closure struct {
+	fn       *compiler.Function
+	upvalues []*upvalue
+	module   *module
+}
+
+// module is a script or an imported module where closures are declared.
+type module struct {
+	globals *env.Environment
+	// file is reported in runtime errors.
+	file string
+}
+
+// upvalue is a variable captured by a closure. It refers to the slot of the stack while
+// the variable is alive and holds the value by itself after the variable goes out of scope.
+type upvalue struct {
+	slot   int
+	open   bool
+	closed internal.Literal
+}
+
+type boundMethod struct {
+	receiver internal.Literal
+	method   *closure
+}
+
+type frame struct {
+	closure *closure
+	// name is reported in stack traces.
+	name string
+	ip   int
+	// last is an offset of the instruction which is executed by the frame.
+	last int
+	// base is an index of the first slot of the frame in the stack.
+	base int
+}
+
+// handler is installed by a try statement; it catches errors which occur
+// while the stack of frames is not shorter than at the moment of the installation.
+type handler struct {
+	// frames is a number of frames when the handler was installed.
+	frames int
+	// height is a size of the stack when the handler was installed.
+	height int
+	// ip is an offset of the catch or the finally clause.
+	ip      int
+	finally bool
+}
+
+// VM executes functions which are produced by compiler.
+type VM struct {
+	globals *env.Environment
+	file    string
+	stack   []internal.Literal
+	frames  []frame
+	// openUpvalues are sorted by slots.
+	openUpvalues []*upvalue
+	results      []any
+	handlers     []handler
+	modules      *modules.Loader
+	// pending are errors which are rethrown at the end of finally clauses.
+	pending []error
+	// stdout, stderr and stdin are streams of the script: print writes to stdout,
+	// uncaught errors are reported to stderr and readLine reads stdin.
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+	budget interpreter.Budget
+	memory interpreter.Memory
+	// runtime is passed to native functions.
+	runtime *interpreter.Runtime
+}
+
+type Option func(*VM)
+
+// WithFile sets a name of the script which is reported in runtime errors.
+func WithFile(name string) Option {
+	return func(vm *VM) {
+		vm.file = name
+	}
+}
+
+// WithModules sets the loader of imported modules; by default modules are searched near the importing file.
+func WithModules(l *modules.Loader) Option {
+	return func(vm *VM) {
+		vm.modules = l
+	}
+}
+
+// WithStdout sets the writer of print statements; it is os.Stdout by default.
+func WithStdout(w io.Writer) Option {
+	return func(vm *VM) {
+		vm.stdout = w
+	}
+}
+
+// WithStderr sets the stream where uncaught errors are reported; it is os.Stderr by default.
+func WithStderr(w io.Writer) Option {
+	return func(vm *VM) {
+		vm.stderr = w
+	}
+}
+
+// WithStdin sets the input stream which is read by the builtin readLine; it is os.Stdin by default.
+func WithStdin(r io.Reader) Option {
+	return func(vm *VM) {
+		vm.stdin = r
+	}
+}
+
+// WithContext stops the script with interpreter.ErrCanceled when ctx is done.
+func WithContext(ctx context.Context) Option {
+	return func(vm *VM) {
+		vm.budget.Context = ctx
+	}
+}
+
+// WithMaxSteps stops the script with interpreter.ErrBudgetExceeded after n iterations of loops and calls.
+func WithMaxSteps(n int) Option {
+	return func(vm *VM) {
+		vm.budget.MaxSteps = n
+	}
+}
+
+// WithMaxCallDepth overrides interpreter.DefaultMaxCallDepth; zero does not limit the depth.
+func WithMaxCallDepth(n int) Option {
+	return func(vm *VM) {
+		vm.budget.MaxCallDepth = n
+	}
+}
+
+// WithDeadline stops the script with interpreter.ErrBudgetExceeded after the time t.
+func WithDeadline(t time.Time) Option {
+	return func(vm *VM) {
+		vm.budget.Deadline = t
+	}
+}
+
+// WithMemoryLimit stops the script with interpreter.ErrMemoryLimit when it allocates more than n bytes.
+func WithMemoryLimit(n int64) Option {
+	return func(vm *VM) {
+		vm.memory.Limit = n
+	}
+}
+
+func New(globals *env.Environment, opts ...Option) *VM {
+	vm := &VM{globals: globals, stdout: os.Stdout, stderr: os.Stderr, stdin: os.Stdin}
+	vm.budget.MaxCallDepth = interpreter.DefaultMaxCallDepth
+	for _, opt := range opts {
+		opt(vm)
+	}
+	vm.runtime = interpreter.NewRuntime(&vm.budget, &vm.memory, vm.stdin)
+	if vm.modules == nil {
+		builtins := env.New()
+		interpreter.DefineBuiltins(builtins)
+		vm.modules = modules.New(builtins)
+	}
+	interpreter.DefineBuiltins(globals)
+
+	return vm
+}
+
+// Run executes the script and returns values of its top-level expression statements
+// like interpreter.Interpreter.Interpret does; the uncaught error is reported to stderr and returned.
+func (vm *VM) Run(script *compiler.Function) ([]any, error) {
+	vm.stack, vm.frames, vm.openUpvalues, vm.results = vm.stack[:0], vm.frames[:0], nil, nil
+	vm.handlers, vm.pending = nil, nil
+
+	vm.push(internal.LiteralNil)
+	vm.frames = append(vm.frames, frame{closure: &closure{fn: script, module: &module{globals: vm.globals, file: vm.file}}, name: scriptFrame})
+
+	if err := vm.run(0); err != nil {
+		interpreter.Report(vm.stderr, err)
+		return nil, err
+	}
+
+	return vm.results, nil
+}
+
+// Call calls the function or the class with arguments outside of a script.
+func (vm *VM) Call(callee internal.Literal, args []internal.Literal) (internal.Literal, error) {
+	vm.stack, vm.frames, vm.openUpvalues = vm.stack[:0], vm.frames[:0], nil
+	vm.handlers, vm.pending = nil, nil
+
+	vm.push(callee)
+	for _, a := range args {
+		vm.push(a)
+	}
+
+	if err := vm.call(callee, len(args)); err != nil {
+		return internal.LiteralNil, err
+	}
+	if len(vm.frames) != 0 {
+		if err := vm.run(0); err != nil {
+			return internal.LiteralNil, err
+		}
+	}
+
+	return vm.pop(), nil
+}
+
+// run executes instructions until the number of frames falls to base; the result of the last frame is left on the stack.
+func (vm *VM) run(base int) error {
+	for {
+		err := vm.execute(base)
+		if err == nil || !vm.handle(err, base) {
+			return err
+		}
+	}
+}
+
+// handle unwinds the stack to the innermost handler above base and reports whether the error is caught.
+func (vm *VM) handle(err error, base int) bool {
+	if len(vm.handlers) == 0 || vm.handlers[len(vm.handlers)-1].frames <= base {
+		return false
+	}
+	value, ok := interpreter.Catch(err)
+	if !ok {
+		return false
+	}
+
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	vm.closeUpvalues(h.height)
+	vm.stack = vm.stack[:h.height]
+	vm.frames = vm.frames[:h.frames]
+	vm.frames[h.frames-1].ip = h.ip
+	if h.finally {
+		vm.pending = append(vm.pending, err)
+		value = internal.NewLiteralInt(int64(len(vm.pending) - 1))
+	}
+	vm.push(value)
+
+	return true
+}
+
+// execute runs instructions until the number of frames falls to base or an error occurs.
+func (vm *VM) execute(base int) error {
+	for {
+		f := &vm.frames[len(vm.frames)-1]
+		chunk := &f.closure.fn.Chunk
+		f.last = f.ip
+		op := compiler.OpCode(vm.readByte(f))
+
+		switch op {
+		case compiler.OpConstant:
+			vm.push(chunk.Constants[vm.readU16(f)])
+		case compiler.OpNil:
+			vm.push(internal.LiteralNil)
+		case compiler.OpTrue:
+			vm.push(internal.NewLiteralBool(true))
+		case compiler.OpFalse:
+			vm.push(internal.NewLiteralBool(false))
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpGetLocal:
+			vm.push(vm.stack[f.base+int(vm.readByte(f))])
+		case compiler.OpSetLocal:
+			vm.stack[f.base+int(vm.readByte(f))] = vm.peek(0)
+		case compiler.OpGetUpvalue:
+			vm.push(vm.getUpvalue(f.closure.upvalues[vm.readByte(f)]))
+		case compiler.OpSetUpvalue:
+			vm.setUpvalue(f.closure.upvalues[vm.readByte(f)], vm.peek(0))
+		case compiler.OpDefineGlobal:
+			f.closure.module.globals.Define(vm.readName(f), vm.pop())
+		case compiler.OpGetGlobal:
+			v, err := f.closure.module.globals.Get(vm.readName(f))
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpSetGlobal:
+			if err := f.closure.module.globals.Assign(vm.readName(f), vm.peek(0)); err != nil {
+				return vm.runtimeError(err)
+			}
+		case compiler.OpGetProperty:
+			v, err := vm.getProperty(vm.pop(), vm.readName(f))
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpSetProperty:
+			name := vm.readName(f)
+			value, obj := vm.pop(), vm.pop()
+			if !obj.IsClassInstance() {
+				return vm.runtimeError(errors.New("only instances have fields"))
+			}
+			if err := vm.memory.AllocField(obj.AsClassInstance(), name); err != nil {
+				return vm.runtimeError(err)
+			}
+			obj.AsClassInstance().Set(name, value)
+			vm.push(internal.LiteralNil)
+		case compiler.OpGetSuper:
+			name := vm.readName(f)
+			superclass, instance := vm.pop(), vm.pop()
+			method, ok := superclass.AsClass().FindMethod(name)
+			if !ok {
+				return vm.runtimeError(errors.New("undefined property: " + name))
+			}
+			vm.push(bind(method, instance))
+		case compiler.OpBinary:
+			operator := kind.TokenType(vm.readByte(f))
+			right, left := vm.pop(), vm.pop()
+			v, err := interpreter.Binary(operator, left, right)
+			if err == nil {
+				err = vm.memory.AllocValue(v)
+			}
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpUnary:
+			v, err := interpreter.Unary(kind.TokenType(vm.readByte(f)), vm.pop())
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpJump:
+			offset := vm.readU16(f)
+			f.ip += int(offset)
+		case compiler.OpJumpIfFalse:
+			offset := vm.readU16(f)
+			if !vm.peek(0).AsBool() {
+				f.ip += int(offset)
+			}
+		case compiler.OpLoop:
+			offset := vm.readU16(f)
+			f.ip -= int(offset)
+			if err := vm.budget.Step(); err != nil {
+				return vm.runtimeError(err)
+			}
+		case compiler.OpCall:
+			argc := int(vm.readByte(f))
+			if err := vm.budget.Call(len(vm.frames)); err != nil {
+				return vm.runtimeError(err)
+			}
+			if err := vm.call(vm.peek(argc), argc); err != nil {
+				return vm.runtimeError(err)
+			}
+		case compiler.OpClosure:
+			fn := chunk.Functions[vm.readU16(f)]
+			c := &closure{fn: fn, upvalues: make([]*upvalue, fn.Upvalues), module: f.closure.module}
+			for i := range c.upvalues {
+				isLocal, index := vm.readByte(f), int(vm.readByte(f))
+				if isLocal == 1 {
+					c.upvalues[i] = vm.captureUpvalue(f.base + index)
+				} else {
+					c.upvalues[i] = f.closure.upvalues[index]
+				}
+			}
+			vm.push(internal.NewLiteralCompiledFunction(fn.Name, fn.Parameters, c))
+		case compiler.OpCloseUpvalue:
+			vm.closeUpvalues(len(vm.stack) - 1)
+			vm.pop()
+		case compiler.OpReturn:
+			result := vm.pop()
+			vm.closeUpvalues(f.base)
+			vm.stack = vm.stack[:f.base]
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			vm.push(result)
+			if len(vm.frames) == base {
+				return nil
+			}
+		case compiler.OpPushCatch, compiler.OpPushFinally:
+			offset := vm.readU16(f)
+			vm.handlers = append(vm.handlers, handler{
+				frames:  len(vm.frames),
+				height:  len(vm.stack),
+				ip:      f.ip + int(offset),
+				finally: op == compiler.OpPushFinally,
+			})
+		case compiler.OpPopHandler:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpThrow:
+			return vm.runtimeError(&interpreter.Exception{Value: vm.pop()})
+		case compiler.OpRethrow:
+			idx := vm.pop().AsInt()
+			err := vm.pending[idx]
+			vm.pending = vm.pending[:idx]
+			return err
+		case compiler.OpImport:
+			m, err := vm.importModule(f.closure.module.file, vm.readName(f))
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(m)
+		case compiler.OpImportName:
+			v, err := modules.Export(vm.pop(), vm.readName(f))
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpClass:
+			name := vm.readName(f)
+			methods := make(map[string]internal.Literal)
+			count := int(vm.readByte(f))
+			for i := 0; i < count; i++ {
+				methods[vm.readName(f)] = vm.peek(count - 1 - i)
+			}
+
+			var superclass *internal.Class
+			if v := vm.peek(count); !v.IsNil() {
+				if !v.IsClass() {
+					return vm.runtimeError(errors.New("superclass must be a class"))
+				}
+				class := v.AsClass()
+				superclass = &class
+			}
+
+			vm.stack = vm.stack[:len(vm.stack)-count-1]
+			vm.push(internal.NewLiteralClass(name, superclass, methods))
+		case compiler.OpList:
+			count := int(vm.readU16(f))
+			elements := make([]internal.Literal, count)
+			copy(elements, vm.stack[len(vm.stack)-count:])
+			vm.stack = vm.stack[:len(vm.stack)-count]
+			list := internal.NewLiteralList(elements)
+			if err := vm.memory.AllocValue(list); err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(list)
+		case compiler.OpInterpolate:
+			count := int(vm.readU16(f))
+			var b strings.Builder
+			for _, v := range vm.stack[len(vm.stack)-count:] {
+				b.WriteString(v.String())
+			}
+			vm.stack = vm.stack[:len(vm.stack)-count]
+			s := internal.NewLiteralString(b.String())
+			if err := vm.memory.AllocValue(s); err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(s)
+		case compiler.OpMap:
+			count := int(vm.readU16(f))
+			entries := vm.stack[len(vm.stack)-2*count:]
+			m := internal.NewMap()
+			for i := 0; i < len(entries); i += 2 {
+				if err := m.Set(entries[i], entries[i+1]); err != nil {
+					return vm.runtimeError(err)
+				}
+			}
+			vm.stack = vm.stack[:len(vm.stack)-2*count]
+			dict := internal.NewLiteralMap(m)
+			if err := vm.memory.AllocValue(dict); err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(dict)
+		case compiler.OpIndex:
+			idx, obj := vm.pop(), vm.pop()
+			v, err := interpreter.Index(obj, idx)
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpSetIndex:
+			value, idx, obj := vm.pop(), vm.pop(), vm.pop()
+			err := vm.memory.AllocKey(obj, idx)
+			if err == nil {
+				err = interpreter.SetIndex(obj, idx, value)
+			}
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(value)
+		case compiler.OpSlice:
+			high, low, obj := vm.pop(), vm.pop(), vm.pop()
+			v, err := interpreter.Slice(obj, low, high)
+			if err == nil {
+				err = vm.memory.AllocValue(v)
+			}
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.push(v)
+		case compiler.OpPrint:
+			if _, err := fmt.Fprintln(vm.stdout, vm.pop().String()); err != nil {
+				return err
+			}
+		case compiler.OpResult:
+			if v := vm.pop(); !v.IsNil() {
+				vm.results = append(vm.results, v)
+			}
+		default:
+			return vm.runtimeError(fmt.Errorf("unknown opcode %d", op))
+		}
+	}
+}
+
+// importModule returns the module which is imported by the file importer.
+// Top-level statements of the module are executed in new frames above the frame of the import.
+func (vm *VM) importModule(importer, path string) (internal.Literal, error) {
+	return vm.modules.Import(importer, path, func(file string, stmts []internal.Stmt, globals *env.Environment) error {
+		script, err := compiler.New().Compile(stmts)
+		if err != nil {
+			return err
+		}
+
+		results, base := vm.results, len(vm.frames)
+		defer func() {
+			vm.results = results
+		}()
+
+		vm.push(internal.LiteralNil)
+		vm.frames = append(vm.frames, frame{
+			closure: &closure{fn: script, module: &module{globals: globals, file: file}},
+			name:    scriptFrame,
+			base:    len(vm.stack) - 1,
+		})
+		if err := vm.run(base); err != nil {
+			return err
+		}
+		vm.pop()
+
+		return nil
+	})
+}
+
+// call starts the call of the callee which is followed by argc arguments on the stack.
+func (vm *VM) call(callee internal.Literal, argc int) error {
+	switch {
+	case callee.IsFunction():
+		f := callee.AsFunction()
+		switch c := f.Compiled.(type) {
+		case *closure:
+			return vm.callClosure(c, argc, f.Name)
+		case *boundMethod:
+			vm.stack[len(vm.stack)-argc-1] = c.receiver
+			return vm.callClosure(c.method, argc, f.Name)
+		}
+
+		if argc != len(f.ArgumentsName) {
+			return fmt.Errorf("expected %d arguments but got %d", len(f.ArgumentsName), argc)
+		}
+
+		args := make([]internal.Literal, argc)
+		copy(args, vm.stack[len(vm.stack)-argc:])
+		ret, err := vm.memory.CallNative(vm.runtime, f, args)
+		if err != nil {
+			return err
+		}
+
+		vm.stack = vm.stack[:len(vm.stack)-argc-1]
+		vm.push(ret)
+		return nil
+	case callee.IsClass():
+		class := callee.AsClass()
+		instance := internal.NewLiteralClassInstance(&class)
+		if err := vm.memory.AllocValue(instance); err != nil {
+			return err
+		}
+		vm.stack[len(vm.stack)-argc-1] = instance
+		if class.Initializer != nil {
+			return vm.callClosure(class.Initializer.AsFunction().Compiled.(*closure), argc, class.Name)
+		}
+		if argc != 0 {
+			return fmt.Errorf("expected 0 arguments but got %d", argc)
+		}
+
+		return nil
+	}
+
+	return errors.New("this type is not callable")
+}
+
+func (vm *VM) callClosure(c *closure, argc int, name string) error {
+	if name == "" {
+		name = anonymousFrame
+	}
+	if argc != len(c.fn.Parameters) {
+		return fmt.Errorf("expected %d arguments but got %d", len(c.fn.Parameters), argc)
+	}
+
+	vm.frames = append(vm.frames, frame{closure: c, name: name, base: len(vm.stack) - argc - 1})
+	return nil
+}
+
+func (vm *VM) getProperty(obj internal.Literal, name string) (internal.Literal, error) {
+	if obj.IsString() {
+		return interpreter.StringMethod(obj, name)
+	}
+	if !obj.IsClassInstance() {
+		return internal.LiteralNil, errors.New("only instances and strings have properties")
+	}
+
+	instance := obj.AsClassInstance()
+	ret, err := instance.Get(name)
+	if err == nil {
+		return ret, nil
+	}
+
+	method, ok := instance.Class.FindMethod(name)
+	if !ok {
+		return internal.LiteralNil, err
+	}
+
+	return bind(method, obj), nil
+}
+
+// bind returns the method which gets the instance as "this".
+func bind(method internal.Literal, instance internal.Literal) internal.Literal {
+	f := method.AsFunction()
+	return internal.NewLiteralCompiledFunction(f.Name, f.ArgumentsName, &boundMethod{
+		receiver: instance,
+		method:   f.Compiled.(*closure),
+	})
+}
+
+func (vm *VM) captureUpvalue(slot int) *upvalue {
+	idx := 0
+	for idx < len(vm.openUpvalues) && vm.openUpvalues[idx].slot < slot {
+		idx++
+	}
+	if idx < len(vm.openUpvalues) && vm.openUpvalues[idx].slot == slot {
+		return vm.openUpvalues[idx]
+	}
+
+	u := &upvalue{slot: slot, open: true}
+	vm.openUpvalues = append(vm.openUpvalues, nil)
+	copy(vm.openUpvalues[idx+1:], vm.openUpvalues[idx:])
+	vm.openUpvalues[idx] = u
+
+	return u
+}
+
+// closeUpvalues moves variables from slots starting with last to the heap.
+func (vm *VM) closeUpvalues(last int) {
+	idx := len(vm.openUpvalues)
+	for idx > 0 && vm.openUpvalues[idx-1].slot >= last {
+		u := vm.openUpvalues[idx-1]
+		u.closed, u.open = vm.stack[u.slot], false
+		idx--
+	}
+	vm.openUpvalues = vm.openUpvalues[:idx]
+}
+
+func (vm *VM) getUpvalue(u *upvalue) internal.Literal {
+	if u.open {
+		return vm.stack[u.slot]
+	}
+
+	return u.closed
+}
+
+func (vm *VM) setUpvalue(u *upvalue, v internal.Literal) {
+	if u.open {
+		vm.stack[u.slot] = v
+	} else {
+		u.closed = v
+	}
+}
+
+// runtimeError attaches the position of the current instruction and the call stack to err.
+// Runtime errors of imported modules are returned as is, so the innermost position is kept.
+func (vm *VM) runtimeError(err error) error {
+	var rErr *interpreter.RuntimeError
+	if errors.As(err, &rErr) {
+		return err
+	}
+
+	var stack []interpreter.Frame
+	for i := len(vm.frames) - 1; i >= 0; i-- {
+		f := vm.frames[i]
+		span := f.closure.fn.Chunk.Spans[f.last]
+		stack = append(stack, interpreter.Frame{Function: f.name, File: f.closure.module.file, Line: span.Line, Column: span.Column})
+	}
+
+	f := vm.frames[len(vm.frames)-1]
+	span := f.closure.fn.Chunk.Spans[f.last]
+	return &interpreter.RuntimeError{
+		File:   f.closure.module.file,
+		Line:   span.Line,
+		Column: span.Column,
+		Near:   span.Near,
+		Err:    err,
+		Stack:  stack,
+	}
+}
+
+func (vm *VM) readByte(f *frame) byte {
+	b := f.closure.fn.Chunk.Code[f.ip]
+	f.ip++
+
+	return b
+}
+
+func (vm *VM) readU16(f *frame) uint16 {
+	code := f.closure.fn.Chunk.Code
+	f.ip += 2
+
+	return uint16(code[f.ip-2])<<8 | uint16(code[f.ip-1])
+}
+
+func (vm *VM) readName(f *frame) string {
+	return f.closure.fn.Chunk.Constants[vm.readU16(f)].AsString()
+}
+
+func (vm *VM) push(v internal.Literal) {
+	vm.stack = append(vm.stack, v)
+}
+
+func (vm *VM) pop() internal.Literal {
+	v := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+
+	return v
+}
+
+func (vm *VM) peek(distance int) internal.Literal {
+	return vm.stack[len(vm.stack)-1-distance]
+}
@@ -0,0 +1,177 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikgalushko/gan-ilox/compiler"
+	"github.com/nikgalushko/gan-ilox/env"
+	"github.com/nikgalushko/gan-ilox/internal"
+	"github.com/nikgalushko/gan-ilox/internal/programtest"
+	"github.com/nikgalushko/gan-ilox/interpreter"
+	"github.com/nikgalushko/gan-ilox/modules"
+	"github.com/nikgalushko/gan-ilox/parser"
+	"github.com/nikgalushko/gan-ilox/resolver"
+	"github.com/nikgalushko/gan-ilox/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func compile(t *testing.T, code string) *compiler.Function {
+	t.Helper()
+
+	tokens, err := scanner.NewScanner(code).ScanTokens()
+	require.NoError(t, err)
+
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+
+	stmts, err = resolver.New().Resolve(stmts)
+	require.NoError(t, err)
+
+	script, err := compiler.New().Compile(stmts)
+	require.NoError(t, err)
+
+	return script
+}
+
+func run(t *testing.T, code string) ([]any, error) {
+	t.Helper()
+
+	builtins := env.New()
+	return New(env.NewWithParent(builtins), WithModules(modules.New(builtins, programtest.ModulePath)), WithStderr(io.Discard)).Run(compile(t, code))
+}
+
+func TestVM_Programs(t *testing.T) {
+	programtest.Run(t, run)
+}
+
+func TestVM_Golden(t *testing.T) {
+	programtest.Golden(t, "../interpreter/testdata", func(t *testing.T, code string, out io.Writer) {
+		_, _ = New(env.New(), WithStdout(out), WithStderr(out)).Run(compile(t, code))
+	})
+}
+
+func TestVM_SharedModules(t *testing.T) {
+	builtins := env.New()
+	interpreter.DefineBuiltins(builtins)
+	loader := modules.New(builtins, programtest.ModulePath)
+	script := compile(t, `from "input.lox" import read; read();`)
+
+	first := New(env.NewWithParent(builtins), WithModules(loader), WithStdin(strings.NewReader("first")))
+	second := New(env.NewWithParent(builtins), WithModules(loader), WithStdin(strings.NewReader("second")))
+
+	ret, err := first.Run(script)
+	require.NoError(t, err)
+	require.Equal(t, []any{internal.NewLiteralString("first")}, ret)
+
+	ret, err = second.Run(script)
+	require.NoError(t, err)
+	require.Equal(t, []any{internal.NewLiteralString("second")}, ret)
+}
+
+func TestVM_RuntimeErrorStack(t *testing.T) {
+	script := compile(t, `class Box {
+	open() {
+		return this.lid;
+	}
+}
+fun unpack(b) {
+	return b.open();
+}
+unpack(Box());
+`)
+
+	var stderr strings.Builder
+	_, err := New(env.New(), WithFile("box.lox"), WithStderr(&stderr)).Run(script)
+
+	var rErr *interpreter.RuntimeError
+	require.ErrorAs(t, err, &rErr)
+	require.Equal(t, `box.lox:3:15: undefined field: lid near 'lid'
+	at Box.open (box.lox:3:15)
+	at unpack (box.lox:7:15)
+	at <script> (box.lox:9:7)`, rErr.Trace())
+	require.Equal(t, rErr.Trace()+"\n", stderr.String())
+
+	_, err = New(env.New(), WithStderr(io.Discard)).Run(compile(t, `var f = () => nil + 1; f();`))
+	require.ErrorAs(t, err, &rErr)
+	require.Equal(t, `1:19: type missmatch near '+'
+	at <anonymous> (1:19)
+	at <script> (1:25)`, rErr.Trace())
+}
+
+func TestVM_Exceptions(t *testing.T) {
+	globals := env.New()
+	globals.Define("fail", internal.NewLiteralNativeFunction("fail", nil, func(...internal.Literal) (internal.Literal, error) {
+		return internal.LiteralNil, errors.New("native failure")
+	}))
+
+	ret, err := New(globals, WithStderr(io.Discard)).Run(compile(t, `var r; try { fail(); } catch (e) { r = e.message; } r;`))
+	require.NoError(t, err)
+	require.Equal(t, []any{internal.NewLiteralString("native failure")}, ret)
+
+	_, err = New(env.New(), WithStderr(io.Discard)).Run(compile(t, `fun f() { throw "boom"; } f();`))
+	var rErr *interpreter.RuntimeError
+	require.ErrorAs(t, err, &rErr)
+	require.Equal(t, `1:11: uncaught exception: boom near 'throw'
+	at f (1:11)
+	at <script> (1:28)`, rErr.Trace())
+}
+
+func TestVM_Budget(t *testing.T) {
+	programtest.RunBudgets(t, func(t *testing.T, code string, b programtest.Budget) ([]any, error) {
+		opts := []Option{WithStderr(io.Discard)}
+		if b.MaxSteps != 0 {
+			opts = append(opts, WithMaxSteps(b.MaxSteps))
+		}
+		if b.MaxCallDepth != 0 {
+			opts = append(opts, WithMaxCallDepth(b.MaxCallDepth))
+		}
+
+		return New(env.New(), opts...).Run(compile(t, code))
+	})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		code string
+		opt  Option
+		err  error
+		msg  string
+	}{
+		{
+			name: "steps",
+			code: `for {}`,
+			opt:  WithMaxSteps(100),
+			err:  interpreter.ErrBudgetExceeded,
+		},
+		{
+			name: "deadline",
+			code: `while (true) {}`,
+			opt:  WithDeadline(time.Now().Add(10 * time.Millisecond)),
+			err:  interpreter.ErrBudgetExceeded,
+		},
+		{
+			name: "context",
+			code: `for {}`,
+			opt:  WithContext(canceled),
+			err:  interpreter.ErrCanceled,
+			msg:  "1:1: execution canceled: context canceled near 'for'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(env.New(), tt.opt, WithStderr(io.Discard)).Run(compile(t, tt.code))
+			require.ErrorIs(t, err, tt.err)
+			if tt.msg != "" {
+				require.EqualError(t, err, tt.msg)
+			}
+		})
+	}
+}